@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// User represents structure used by users DB in Authz service to handle incoming requests
+type User struct {
+	Login     string
+	Password  string
+	Roles     []string `json:",omitempty"`
+	Groups    []string `json:",omitempty"`
+	OTP       string   `json:",omitempty"` // one-time code of second authentication factor
+	Challenge string   `json:",omitempty"` // MFA challenge id issued by Authz service
+}
+
+// UsersRecord represents users records returned by Authz service
+type UsersRecord struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   []User `json:"data"`
+}
+
+// NamesRecord represents list of names, e.g. roles, returned by Authz service
+type NamesRecord struct {
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+	Data   []string `json:"data"`
+}
+
+// Group represents group of users managed by Authz service
+type Group struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Members     []string `json:"members,omitempty"`
+}
+
+// GroupsRecord represents group records returned by Authz service
+type GroupsRecord struct {
+	Status string  `json:"status"`
+	Error  string  `json:"error,omitempty"`
+	Data   []Group `json:"data"`
+}
+
+// APIKey represents API key record managed by Authz service
+type APIKey struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Scopes  []string `json:"scopes"`
+	Expires int64    `json:"expires,omitempty"`
+	Created int64    `json:"created,omitempty"`
+	Secret  string   `json:"secret,omitempty"`
+}
+
+// APIKeyRecord represents API key records returned by Authz service
+type APIKeyRecord struct {
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+	Data   []APIKey `json:"data"`
+}
+
+// helper function to construct Authz URL from escaped path segments
+func (c *Client) authzURL(segments ...string) string {
+	rurl := c.Services.AuthzURL
+	for _, s := range segments {
+		rurl = fmt.Sprintf("%s/%s", rurl, url.PathEscape(s))
+	}
+	return rurl
+}
+
+// helper function to send request to Authz service which only returns status
+func (c *Client) authzStatus(ctx context.Context, method, rurl string, in any, scope string) error {
+	var response Response
+	return c.call(ctx, method, rurl, in, scope, &response)
+}
+
+// ListUsers returns all users of Authz service, it requires admin scope
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var rec UsersRecord
+	err := c.call(ctx, http.MethodGet, c.authzURL("users"), nil, ScopeAdmin, &rec)
+	return rec.Data, err
+}
+
+// AddUser adds new user to Authz service, it requires admin scope
+func (c *Client) AddUser(ctx context.Context, user User) error {
+	return c.authzStatus(ctx, http.MethodPost, c.authzURL("user"), user, ScopeAdmin)
+}
+
+// DeleteUser removes user from Authz service, it requires admin scope
+func (c *Client) DeleteUser(ctx context.Context, login string) error {
+	return c.authzStatus(ctx, http.MethodDelete, c.authzURL("user", login), nil, ScopeAdmin)
+}
+
+// ChangePassword changes password of given user, it requires write scope
+// of token obtained by the user
+func (c *Client) ChangePassword(ctx context.Context, login, password string) error {
+	user := User{Login: login, Password: password}
+	return c.authzStatus(ctx, http.MethodPut, c.authzURL("user", login, "password"), user, ScopeWrite)
+}
+
+// UserRoles returns roles of given user
+func (c *Client) UserRoles(ctx context.Context, login string) ([]string, error) {
+	var rec NamesRecord
+	err := c.call(ctx, http.MethodGet, c.authzURL("user", login, "roles"), nil, ScopeRead, &rec)
+	return rec.Data, err
+}
+
+// GrantRole grants role to given user, it requires admin scope
+func (c *Client) GrantRole(ctx context.Context, login, role string) error {
+	return c.authzStatus(ctx, http.MethodPost, c.authzURL("user", login, "roles", role), nil, ScopeAdmin)
+}
+
+// RevokeRole revokes role from given user, it requires admin scope
+func (c *Client) RevokeRole(ctx context.Context, login, role string) error {
+	return c.authzStatus(ctx, http.MethodDelete, c.authzURL("user", login, "roles", role), nil, ScopeAdmin)
+}
+
+// ListGroups returns all groups of Authz service
+func (c *Client) ListGroups(ctx context.Context) ([]Group, error) {
+	var rec GroupsRecord
+	err := c.call(ctx, http.MethodGet, c.authzURL("groups"), nil, ScopeRead, &rec)
+	return rec.Data, err
+}
+
+// GroupMembers returns logins of members of given group
+func (c *Client) GroupMembers(ctx context.Context, name string) ([]string, error) {
+	var rec NamesRecord
+	err := c.call(ctx, http.MethodGet, c.authzURL("group", name, "members"), nil, ScopeRead, &rec)
+	return rec.Data, err
+}
+
+// AddGroup adds new group to Authz service, it requires admin scope
+func (c *Client) AddGroup(ctx context.Context, name string) error {
+	return c.authzStatus(ctx, http.MethodPost, c.authzURL("group", name), nil, ScopeAdmin)
+}
+
+// DeleteGroup removes group from Authz service, it requires admin scope
+func (c *Client) DeleteGroup(ctx context.Context, name string) error {
+	return c.authzStatus(ctx, http.MethodDelete, c.authzURL("group", name), nil, ScopeAdmin)
+}
+
+// AddGroupMember adds user to given group, it requires admin scope
+func (c *Client) AddGroupMember(ctx context.Context, name, login string) error {
+	return c.authzStatus(ctx, http.MethodPost, c.authzURL("group", name, "members", login), nil, ScopeAdmin)
+}
+
+// RemoveGroupMember removes user from given group, it requires admin scope
+func (c *Client) RemoveGroupMember(ctx context.Context, name, login string) error {
+	return c.authzStatus(ctx, http.MethodDelete, c.authzURL("group", name, "members", login), nil, ScopeAdmin)
+}
+
+// CreateAPIKey creates new API key, the returned key contains its secret
+// which is not shown again by Authz service. It requires write scope.
+func (c *Client) CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	var rec APIKeyRecord
+	if err := c.call(ctx, http.MethodPost, c.authzURL("apikeys"), key, ScopeWrite, &rec); err != nil {
+		return APIKey{}, err
+	}
+	if len(rec.Data) == 0 {
+		return APIKey{}, errors.New("Authz service did not return created API key")
+	}
+	return rec.Data[0], nil
+}
+
+// ListAPIKeys returns API keys of the user
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var rec APIKeyRecord
+	err := c.call(ctx, http.MethodGet, c.authzURL("apikeys"), nil, ScopeRead, &rec)
+	return rec.Data, err
+}
+
+// RevokeAPIKey revokes API key with given id, it requires write scope
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) error {
+	return c.authzStatus(ctx, http.MethodDelete, c.authzURL("apikeys", id), nil, ScopeWrite)
+}
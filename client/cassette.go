@@ -0,0 +1,242 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ErrUnmatchedRequest is returned by Replayer when cassette does not have
+// recorded response of the request
+var ErrUnmatchedRequest = errors.New("no recorded response in cassette")
+
+// redactedHeaders lists HTTP headers whose values are never stored in cassette
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// RecordedRequest represents HTTP request stored in cassette
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse represents HTTP response stored in cassette
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction represents HTTP request and its response stored in cassette
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette represents HTTP interactions recorded during a command
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// helper function to redact sensitive values of HTTP headers
+func redactHeaders(h http.Header, redact func(string) string) http.Header {
+	out := make(http.Header, len(h))
+	for key, vals := range h {
+		for _, v := range vals {
+			out.Add(key, redact(v))
+		}
+	}
+	for _, key := range redactedHeaders {
+		if out.Get(key) != "" {
+			out.Set(key, "***")
+		}
+	}
+	return out
+}
+
+// helper function to return redact function or identity function if it is not set
+func redactFunc(redact func(string) string) func(string) string {
+	if redact == nil {
+		return func(s string) string { return s }
+	}
+	return redact
+}
+
+// recording holds interactions recorded into one cassette, it is shared by
+// recorders of all HTTP clients of a command
+type recording struct {
+	mu  sync.Mutex    // protects raw and writes of cassette file
+	raw []Interaction // recorded interactions before redaction
+}
+
+// Recorder is HTTP transport which records all requests and responses into
+// cassette file, the file is rewritten after every request such that it is
+// complete even if the process exits abruptly
+type Recorder struct {
+	Base   http.RoundTripper     // underlying transport, http.DefaultTransport if nil
+	File   string                // cassette file name
+	Redact func(s string) string // function to redact sensitive values before saving
+	mu     sync.Mutex            // protects rec
+	rec    *recording            // interactions shared with recorders created by WithBase
+}
+
+// helper function to return recording of the cassette
+func (r *Recorder) recording() *recording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rec == nil {
+		r.rec = &recording{}
+	}
+	return r.rec
+}
+
+// WithBase returns Recorder which sends requests via given transport and
+// records them into the same cassette as r, it allows HTTP clients with
+// different transports to record into one cassette concurrently
+func (r *Recorder) WithBase(base http.RoundTripper) *Recorder {
+	return &Recorder{Base: base, File: r.File, Redact: r.Redact, rec: r.recording()}
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	rec := r.recording()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.raw = append(rec.raw, Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header.Clone(),
+			Body:    string(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	})
+	if err := r.save(rec.raw); err != nil {
+		return nil, fmt.Errorf("unable to write cassette %s: %w", r.File, err)
+	}
+	return resp, nil
+}
+
+// helper function to redact recorded interactions and write them to cassette
+// file, the redaction is applied on every save since secrets may become known
+// after interaction was recorded, e.g. tokens obtained from Authz service
+func (r *Recorder) save(raw []Interaction) error {
+	redact := redactFunc(r.Redact)
+	var cassette Cassette
+	for _, i := range raw {
+		cassette.Interactions = append(cassette.Interactions, Interaction{
+			Request: RecordedRequest{
+				Method:  i.Request.Method,
+				URL:     redact(i.Request.URL),
+				Headers: redactHeaders(i.Request.Headers, redact),
+				Body:    redact(i.Request.Body),
+			},
+			Response: RecordedResponse{
+				StatusCode: i.Response.StatusCode,
+				Headers:    redactHeaders(i.Response.Headers, redact),
+				Body:       redact(i.Response.Body),
+			},
+		})
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.File, data, 0600)
+}
+
+// Replayer is HTTP transport which serves responses from cassette file instead
+// of the network, requests are matched by method and URL in recorded order
+type Replayer struct {
+	Redact       func(s string) string // function used to redact URLs when cassette was recorded
+	mu           sync.Mutex            // protects used
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer creates Replayer from given cassette file
+func NewReplayer(fname string, redact func(s string) string) (*Replayer, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("unable to parse cassette %s: %w", fname, err)
+	}
+	return &Replayer{
+		Redact:       redact,
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	rurl := redactFunc(r.Redact)(req.URL.String())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for idx, i := range r.interactions {
+		if r.used[idx] || i.Request.Method != req.Method || i.Request.URL != rurl {
+			continue
+		}
+		r.used[idx] = true
+		header := i.Response.Headers
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(i.Response.Body))),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrUnmatchedRequest, req.Method, rurl)
+}
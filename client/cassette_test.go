@@ -0,0 +1,251 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// helper function to redact test secret
+func testRedact(s string) string {
+	return strings.ReplaceAll(s, "s3cret", "***")
+}
+
+// helper function to write cassette file for tests
+func testCassette(t *testing.T, interactions []Interaction) string {
+	fname := filepath.Join(t.TempDir(), "cassette.json")
+	data, err := json.Marshal(Cassette{Interactions: interactions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fname, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return fname
+}
+
+// TestCassetteRoundTrip tests that responses recorded by Recorder are
+// replayed by Replayer and that secrets are not stored in cassette
+func TestCassetteRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=s3cret")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(data)))
+	}))
+	defer srv.Close()
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{http.MethodGet, "/meta", "", 200, "GET /meta "},
+		{http.MethodPost, "/meta", `{"password":"s3cret"}`, 201, `POST /meta {"password":"***"}`},
+		{http.MethodGet, "/site/s3cret", "", 200, "GET /site/*** "},
+	}
+	send := func(rt http.RoundTripper) {
+		for _, r := range requests {
+			req, err := http.NewRequest(r.method, srv.URL+r.path, strings.NewReader(r.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer s3cret-token")
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != r.status {
+				t.Errorf("%s %s: status %d, want %d", r.method, r.path, resp.StatusCode, r.status)
+			}
+			// recorder returns actual body, replayer returns redacted one
+			if got := testRedact(string(data)); got != r.want {
+				t.Errorf("%s %s: body %q, want %q", r.method, r.path, got, r.want)
+			}
+		}
+	}
+
+	fname := filepath.Join(t.TempDir(), "cassette.json")
+	send(&Recorder{File: fname, Redact: testRedact})
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("cassette contains secret: %s", data)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != len(requests) {
+		t.Fatalf("%d recorded interactions, want %d", len(cassette.Interactions), len(requests))
+	}
+	for _, i := range cassette.Interactions {
+		if v := i.Request.Headers.Get("Authorization"); v != "***" {
+			t.Errorf("Authorization header recorded as %q", v)
+		}
+		if v := i.Response.Headers.Get("Set-Cookie"); v != "***" {
+			t.Errorf("Set-Cookie header recorded as %q", v)
+		}
+	}
+
+	replayer, err := NewReplayer(fname, testRedact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close() // replayer must not use the network
+	send(replayer)
+}
+
+// TestReplayer tests matching of requests against recorded interactions
+func TestReplayer(t *testing.T) {
+	fname := testCassette(t, []Interaction{
+		{Request: RecordedRequest{Method: "GET", URL: "http://host/meta"}, Response: RecordedResponse{StatusCode: 401}},
+		{Request: RecordedRequest{Method: "POST", URL: "http://host/meta"}, Response: RecordedResponse{StatusCode: 201}},
+		{Request: RecordedRequest{Method: "GET", URL: "http://host/meta"}, Response: RecordedResponse{StatusCode: 200}},
+	})
+	replayer, err := NewReplayer(fname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method string
+		url    string
+		status int // expected status, 0 if request is not matched
+	}{
+		{"GET", "http://host/meta", 401},
+		{"GET", "http://host/meta", 200},
+		{"GET", "http://host/meta", 0},
+		{"DELETE", "http://host/meta", 0},
+		{"POST", "http://host/site", 0},
+		{"POST", "http://host/meta", 201},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		resp, err := replayer.RoundTrip(req)
+		if tt.status == 0 {
+			if !errors.Is(err, ErrUnmatchedRequest) {
+				t.Errorf("%s %s: expected ErrUnmatchedRequest, got %v", tt.method, tt.url, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.url, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.url, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+// TestNewReplayerInvalidCassette tests that broken cassette is reported
+func TestNewReplayerInvalidCassette(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "cassette.json")
+	os.WriteFile(fname, []byte("not json"), 0600)
+	if _, err := NewReplayer(fname, nil); err == nil {
+		t.Error("expected error for invalid cassette")
+	}
+	if _, err := NewReplayer(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+}
+
+// replayTokens provides redacted tokens like orecast does during replay
+type replayTokens struct{}
+
+// Token implements TokenSource interface
+func (replayTokens) Token(ctx context.Context, scope string) (string, error) {
+	return "***", nil
+}
+
+// RenewToken implements TokenRenewer interface
+func (replayTokens) RenewToken(ctx context.Context, scope string) (string, error) {
+	return "***", nil
+}
+
+// TestReplayRenewedToken tests that recorded 401 response followed by retry
+// with renewed token is replayed
+func TestReplayRenewedToken(t *testing.T) {
+	fname := testCassette(t, []Interaction{
+		{Request: RecordedRequest{Method: "DELETE", URL: "http://host/site/foo"}, Response: RecordedResponse{StatusCode: 401, Body: `{"status":"error"}`}},
+		{Request: RecordedRequest{Method: "DELETE", URL: "http://host/site/foo"}, Response: RecordedResponse{StatusCode: 200, Body: `{"status":"ok"}`}},
+	})
+	replayer, err := NewReplayer(fname, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{Tokens: replayTokens{}, HTTPClient: &http.Client{Transport: replayer}}
+	req, _ := http.NewRequest(http.MethodDelete, "http://host/site/foo", nil)
+	if _, err := c.Do(context.Background(), req, ScopeAdmin); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestRecorderWithBase tests that recorders created by WithBase use their own
+// transports and concurrently record into the same cassette
+func TestRecorderWithBase(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	fname := filepath.Join(t.TempDir(), "cassette.json")
+	root := &Recorder{File: fname}
+	var used [2]int32
+	recorders := make([]*Recorder, len(used))
+	for i := range recorders {
+		i := i
+		recorders[i] = root.WithBase(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&used[i], 1)
+			return http.DefaultTransport.RoundTrip(req)
+		}))
+	}
+	const requests = 5
+	var wg sync.WaitGroup
+	for _, r := range recorders {
+		for n := 0; n < requests; n++ {
+			wg.Add(1)
+			go func(r *Recorder) {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+				resp, err := r.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}(r)
+		}
+	}
+	wg.Wait()
+	for i, n := range used {
+		if n != requests {
+			t.Errorf("transport %d used %d times, want %d", i, n, requests)
+		}
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(cassette.Interactions); n != len(recorders)*requests {
+		t.Errorf("%d recorded interactions, want %d", n, len(recorders)*requests)
+	}
+}
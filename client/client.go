@@ -0,0 +1,188 @@
+// Package client provides Go client library for OreCast services. It is used
+// by orecast command line tool and can be used by other Go tools to talk to
+// Discovery, MetaData, DataManagement, DataBookkeeping and Authz services.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	oreConfig "github.com/OreCast/common/config"
+)
+
+// token scopes requested from Authz service
+const (
+	ScopeRead  = "read"  // list OreCast records
+	ScopeWrite = "write" // add or remove OreCast records
+	ScopeAdmin = "admin" // manage OreCast sites
+)
+
+// Response represents response from OreCast service
+type Response struct {
+	Status string `json:"status"`
+	Error  any    `json:"error,omitempty"`
+}
+
+// TokenSource provides access tokens of given scope, e.g. read, write or admin
+type TokenSource interface {
+	Token(ctx context.Context, scope string) (string, error)
+}
+
+// TokenRenewer is implemented by token sources which can renew token
+// rejected by OreCast service
+type TokenRenewer interface {
+	RenewToken(ctx context.Context, scope string) (string, error)
+}
+
+// TokenFunc is an adapter to use ordinary function as TokenSource
+type TokenFunc func(ctx context.Context, scope string) (string, error)
+
+// Token implements TokenSource interface
+func (f TokenFunc) Token(ctx context.Context, scope string) (string, error) {
+	return f(ctx, scope)
+}
+
+// Client represents client of OreCast services
+type Client struct {
+	Services   oreConfig.Services // URLs of OreCast services
+	Tokens     TokenSource        // source of access tokens
+	HTTPClient *http.Client       // HTTP client used to send requests
+	Debug      func(args ...any)  // optional function to print debug messages
+}
+
+// New creates new client of OreCast services with given token source
+func New(services oreConfig.Services, tokens TokenSource) *Client {
+	return &Client{
+		Services:   services,
+		Tokens:     tokens,
+		HTTPClient: NewHTTPClient(DefaultTimeout, DefaultRetries),
+	}
+}
+
+// helper function to print debug message
+func (c *Client) debug(args ...any) {
+	if c.Debug != nil {
+		c.Debug(args...)
+	}
+}
+
+// helper function to send HTTP request
+func (c *Client) send(req *http.Request, token string) (*http.Response, error) {
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	c.debug("HTTP", req.Method, req.URL)
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return hc.Do(req)
+}
+
+// helper function to check if response rejects request due to token scope
+func insufficientScope(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusForbidden {
+		return true
+	}
+	return strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope")
+}
+
+// Do sends HTTP request authorized with token of given scope and returns body
+// of HTTP response, the request is sent without token when scope is empty.
+// If token is rejected by OreCast service and token source implements
+// TokenRenewer the token is renewed and request is retried once. Failed
+// responses are returned as StatusError and network failures wrap ErrNetwork.
+func (c *Client) Do(ctx context.Context, req *http.Request, scope string) ([]byte, error) {
+	req = req.WithContext(ctx)
+	var token string
+	if scope != "" {
+		if c.Tokens == nil {
+			return nil, errors.New("no token source to authorize request")
+		}
+		var err error
+		token, err = c.Tokens.Token(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp, err := c.send(req, token)
+	if err != nil {
+		return nil, networkError(ctx, err)
+	}
+	renewer, ok := c.Tokens.(TokenRenewer)
+	if scope != "" && ok && resp.StatusCode == http.StatusUnauthorized && (req.Body == nil || req.GetBody != nil) {
+		if token, err := renewer.RenewToken(ctx, scope); err == nil {
+			resp.Body.Close()
+			if req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+			c.debug("retry request with refreshed token")
+			if resp, err = c.send(req, token); err != nil {
+				return nil, networkError(ctx, err)
+			}
+		} else {
+			c.debug("unable to renew token", err)
+		}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return body, networkError(ctx, err)
+	}
+	if scope != "" && insufficientScope(resp) {
+		return body, fmt.Errorf("%w: %s %s requires '%s' scope, server response: %s",
+			ErrInsufficientScope, req.Method, req.URL, scope, strings.TrimSpace(string(body)))
+	}
+	return body, CheckResponse(resp, body)
+}
+
+// helper function to wrap error of sending request into ErrNetwork unless
+// request was cancelled or it was not found in replayed cassette
+func networkError(ctx context.Context, err error) error {
+	if ctx.Err() != nil || errors.Is(err, ErrUnmatchedRequest) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrNetwork, err)
+}
+
+// helper function to send request with optional JSON body and decode JSON
+// response into given value
+func (c *Client) call(ctx context.Context, method, rurl string, in any, scope string, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rurl, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.decode(ctx, req, scope, out)
+}
+
+// helper function to send request and decode JSON response into given value
+func (c *Client) decode(ctx context.Context, req *http.Request, scope string, out any) error {
+	data, err := c.Do(ctx, req, scope)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unable to decode response of %s %s: %w, response body %s",
+			req.Method, req.URL, err, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
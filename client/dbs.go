@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DBSRecord represents record returned by DataBookkeeping service
+type DBSRecord map[string]any
+
+// ListDatasets returns datasets from DataBookkeeping service whose names
+// start with given prefix, empty prefix matches all records while records
+// without dataset name are dropped when prefix is given
+func (c *Client) ListDatasets(ctx context.Context, prefix string) ([]DBSRecord, error) {
+	var records, out []DBSRecord
+	rurl := fmt.Sprintf("%s/datasets", c.Services.DataBookkeepingURL)
+	if err := c.call(ctx, http.MethodGet, rurl, nil, "", &records); err != nil {
+		return nil, err
+	}
+	if prefix == "" {
+		return records, nil
+	}
+	for _, rec := range records {
+		if name, ok := rec["dataset"].(string); ok && strings.HasPrefix(name, prefix) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
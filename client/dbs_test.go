@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oreConfig "github.com/OreCast/common/config"
+)
+
+// TestListDatasets tests filtering of datasets by name prefix
+func TestListDatasets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/datasets" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"dataset": "/ore/a", "size": 1},
+			{"dataset": "/ore/b", "size": 2},
+			{"dataset": "/other/c", "size": 3},
+			{"name": "/ore/no-dataset-key"},
+			{"dataset": 42}
+		]`))
+	}))
+	defer srv.Close()
+	c := New(oreConfig.Services{DataBookkeepingURL: srv.URL}, nil)
+	tests := []struct {
+		name   string
+		prefix string
+		want   int
+	}{
+		{"no prefix matches all records", "", 5},
+		{"prefix", "/ore/", 2},
+		{"exact name", "/other/c", 1},
+		{"no match", "/missing", 0},
+		{"records without dataset name are dropped", "/", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := c.ListDatasets(context.Background(), tt.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != tt.want {
+				t.Errorf("%d records, want %d: %v", len(records), tt.want, records)
+			}
+			for _, rec := range records {
+				if _, ok := rec["dataset"].(string); tt.prefix != "" && !ok {
+					t.Errorf("record without dataset name %v", rec)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// classes of failed requests, StatusError and network errors wrap them such
+// that callers can check failure class with errors.Is
+var (
+	ErrAuth     = errors.New("not authorized")
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+	ErrServer   = errors.New("server error")
+	ErrNetwork  = errors.New("network error")
+)
+
+// ErrInsufficientScope is returned when OreCast service rejects token scope
+var ErrInsufficientScope = fmt.Errorf("%w: insufficient scope", ErrAuth)
+
+// maxErrorBody defines how much of non-JSON response body is shown in errors
+const maxErrorBody = 256
+
+// StatusError represents failed request to OreCast service, either with
+// HTTP error status code or with failed status reported in response body
+type StatusError struct {
+	Method     string // HTTP method of the request
+	URL        string // URL of the request
+	StatusCode int    // HTTP status code of the response
+	Status     string // status reported by OreCast service, e.g. error
+	Message    string // error message reported by OreCast service
+}
+
+// Error implements error interface
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s %s failed", e.Method, e.URL)
+	if e.StatusCode >= http.StatusBadRequest {
+		msg = fmt.Sprintf("%s with %d %s", msg, e.StatusCode, http.StatusText(e.StatusCode))
+	} else if e.Status != "" {
+		msg = fmt.Sprintf("%s with status '%s'", msg, e.Status)
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	return msg
+}
+
+// Unwrap returns class of the failure based on HTTP status code
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusNotFound, e.StatusCode == http.StatusGone:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict, e.StatusCode == http.StatusPreconditionFailed:
+		return ErrConflict
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	}
+	return nil
+}
+
+// serviceStatus represents status and error fields of OreCast service
+// responses, it covers both Response and UploadRecord
+type serviceStatus struct {
+	Status string `json:"status"`
+	Error  any    `json:"error"`
+	Msg    string `json:"msg"`
+}
+
+// helper function to extract error message from response body
+func errorMessage(body []byte, s serviceStatus) string {
+	var msgs []string
+	switch e := s.Error.(type) {
+	case nil:
+	case string:
+		if e != "" {
+			msgs = append(msgs, e)
+		}
+	default:
+		if data, err := json.Marshal(e); err == nil {
+			msgs = append(msgs, string(data))
+		}
+	}
+	if s.Msg != "" {
+		msgs = append(msgs, s.Msg)
+	}
+	if len(msgs) > 0 {
+		return strings.Join(msgs, ", ")
+	}
+	if s.Status != "" {
+		return ""
+	}
+	// not OreCast response, e.g. error page of a proxy
+	msg := strings.TrimSpace(string(body))
+	if len(msg) > maxErrorBody {
+		msg = msg[:maxErrorBody] + "..."
+	}
+	return msg
+}
+
+// CheckResponse is central handler of OreCast service responses, it returns
+// StatusError when response has HTTP error status code or when status field
+// of Response or UploadRecord in its body reports a failure
+func CheckResponse(resp *http.Response, body []byte) error {
+	var s serviceStatus
+	json.Unmarshal(body, &s)
+	if resp.StatusCode < http.StatusBadRequest && (s.Status == "" || s.Status == "ok") {
+		return nil
+	}
+	err := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     s.Status,
+		Message:    errorMessage(body, s),
+	}
+	if resp.Request != nil {
+		err.Method = resp.Request.Method
+		err.URL = resp.Request.URL.String()
+	}
+	return err
+}
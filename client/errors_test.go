@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// helper function to create response to given request for tests
+func testResponse(code int) *http.Response {
+	rurl, _ := url.Parse("http://localhost:8300/meta")
+	return &http.Response{
+		StatusCode: code,
+		Request:    &http.Request{Method: http.MethodGet, URL: rurl},
+	}
+}
+
+// TestCheckResponse tests detection of failed OreCast service responses
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		body    string
+		fail    bool
+		message string
+		class   error
+	}{
+		{"ok", 200, `{"status":"ok","data":[]}`, false, "", nil},
+		{"empty body", 200, ``, false, "", nil},
+		{"no status", 200, `[{"name":"Cornell"}]`, false, "", nil},
+		{"error status", 200, `{"status":"error","error":"no site"}`, true, "no site", nil},
+		{"error status without message", 200, `{"status":"fail"}`, true, "", nil},
+		{"error and msg", 400, `{"status":"error","error":"bad","msg":"fix it"}`, true, "bad, fix it", nil},
+		{"structured error", 400, `{"status":"error","error":{"code":1}}`, true, `{"code":1}`, nil},
+		{"unauthorized", 401, `{"status":"error","error":"token expired"}`, true, "token expired", ErrAuth},
+		{"forbidden", 403, ``, true, "", ErrAuth},
+		{"not found", 404, `404 page not found`, true, "404 page not found", ErrNotFound},
+		{"gone", 410, ``, true, "", ErrNotFound},
+		{"conflict", 409, `{"status":"error","error":"exists"}`, true, "exists", ErrConflict},
+		{"precondition failed", 412, ``, true, "", ErrConflict},
+		{"server error", 500, `<html>Internal Server Error</html>`, true, "<html>Internal Server Error</html>", ErrServer},
+		{"bad gateway", 502, ``, true, "", ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckResponse(testResponse(tt.code), []byte(tt.body))
+			if !tt.fail {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			var serr *StatusError
+			if !errors.As(err, &serr) {
+				t.Fatalf("expected StatusError, got %v", err)
+			}
+			if serr.StatusCode != tt.code || serr.Method != http.MethodGet || serr.URL != "http://localhost:8300/meta" {
+				t.Errorf("unexpected StatusError %+v", serr)
+			}
+			if serr.Message != tt.message {
+				t.Errorf("message %q, want %q", serr.Message, tt.message)
+			}
+			if serr.Unwrap() != tt.class {
+				t.Errorf("class %v, want %v", serr.Unwrap(), tt.class)
+			}
+			for _, class := range []error{ErrAuth, ErrNotFound, ErrConflict, ErrServer, ErrNetwork} {
+				if errors.Is(err, class) != (class == tt.class) {
+					t.Errorf("errors.Is(err, %v) = %v", class, !(class == tt.class))
+				}
+			}
+		})
+	}
+}
+
+// TestCheckResponseTruncatesBody tests that long non-JSON body is truncated in errors
+func TestCheckResponseTruncatesBody(t *testing.T) {
+	body := strings.Repeat("x", 2*maxErrorBody)
+	var serr *StatusError
+	if !errors.As(CheckResponse(testResponse(502), []byte(body)), &serr) {
+		t.Fatal("expected StatusError")
+	}
+	if serr.Message != body[:maxErrorBody]+"..." {
+		t.Errorf("body was not truncated, message length %d", len(serr.Message))
+	}
+}
+
+// TestStatusErrorMessage tests formatting of StatusError
+func TestStatusErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  StatusError
+		want string
+	}{
+		{
+			StatusError{Method: "GET", URL: "http://host/meta", StatusCode: 404, Message: "no record"},
+			"GET http://host/meta failed with 404 Not Found: no record",
+		},
+		{
+			StatusError{Method: "POST", URL: "http://host/meta", StatusCode: 200, Status: "error"},
+			"POST http://host/meta failed with status 'error'",
+		},
+		{
+			StatusError{Method: "DELETE", URL: "http://host/site", StatusCode: 500},
+			"DELETE http://host/site failed with 500 Internal Server Error",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+	if !errors.Is(ErrInsufficientScope, ErrAuth) {
+		t.Error("ErrInsufficientScope should be ErrAuth failure")
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// MetaData represents MetaData object returned from discovery service
+type MetaData struct {
+	ID          string   `json:"id"`
+	Site        string   `json:"site"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Bucket      string   `json:"bucket"`
+}
+
+// MetaDataRecord represents MetaData record returned by discovery service
+type MetaDataRecord struct {
+	Status string     `json:"status"`
+	Data   []MetaData `json:"data"`
+}
+
+// SiteMeta returns meta-data records of given site from MetaData service
+func (c *Client) SiteMeta(ctx context.Context, site string) ([]MetaData, error) {
+	var rec MetaDataRecord
+	rurl := fmt.Sprintf("%s/meta/%s", c.Services.MetaDataURL, url.PathEscape(site))
+	if err := c.call(ctx, http.MethodGet, rurl, nil, "", &rec); err != nil {
+		return nil, err
+	}
+	return rec.Data, nil
+}
+
+// SiteError represents failure to fetch records of single site
+type SiteError struct {
+	Site string // name of the site
+	Err  error  // failure of the site request
+}
+
+// Error implements error interface
+func (e *SiteError) Error() string {
+	return fmt.Sprintf("site %s: %v", e.Site, e.Err)
+}
+
+// Unwrap returns failure of the site request
+func (e *SiteError) Unwrap() error {
+	return e.Err
+}
+
+// ListMeta returns meta-data records of given site, or of all sites
+// registered in Discovery service when site is empty. A failing site does not
+// stop the listing, records of other sites are returned together with
+// SiteError of every failed site joined by errors.Join.
+func (c *Client) ListMeta(ctx context.Context, site string) ([]MetaData, error) {
+	var records []MetaData
+	sites, err := c.ListSites(ctx)
+	if err != nil {
+		return records, err
+	}
+	var errs []error
+	for _, s := range sites {
+		if site != "" && site != s.Name {
+			continue
+		}
+		c.debug("processing site", s.Name)
+		data, err := c.SiteMeta(ctx, s.Name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return records, err
+			}
+			errs = append(errs, &SiteError{Site: s.Name, Err: err})
+			continue
+		}
+		records = append(records, data...)
+	}
+	return records, errors.Join(errs...)
+}
+
+// AddMeta adds meta-data record to MetaData service, it requires write scope
+func (c *Client) AddMeta(ctx context.Context, meta MetaData) error {
+	var response Response
+	rurl := fmt.Sprintf("%s/meta", c.Services.MetaDataURL)
+	return c.call(ctx, http.MethodPost, rurl, meta, ScopeWrite, &response)
+}
+
+// DeleteMeta removes meta-data record from MetaData service, it requires write scope
+func (c *Client) DeleteMeta(ctx context.Context, id string) error {
+	var response Response
+	rurl := fmt.Sprintf("%s/meta/%s", c.Services.MetaDataURL, url.PathEscape(id))
+	return c.call(ctx, http.MethodDelete, rurl, nil, ScopeWrite, &response)
+}
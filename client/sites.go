@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Site represents Site object returned from discovery service
+type Site struct {
+	Name         string `json:"name" form:"name" binding:"required"`
+	URL          string `json:"url" form:"url" binding:"required"`
+	Endpoint     string `json:"endpoint" form:"endpoint" binding:"required"`
+	AccessKey    string `json:"access_key" form:"access_key" binding:"required"`
+	AccessSecret string `json:"access_secret" form:"access_secret" binding:"required"`
+	UseSSL       bool   `json:"use_ssl" form:"use_ssl"`
+	Description  string `json:"description" form:"description"`
+}
+
+// ListSites returns all sites registered in Discovery service
+func (c *Client) ListSites(ctx context.Context) ([]Site, error) {
+	var sites []Site
+	rurl := fmt.Sprintf("%s/sites", c.Services.DiscoveryURL)
+	err := c.call(ctx, http.MethodGet, rurl, nil, "", &sites)
+	return sites, err
+}
+
+// AddSite registers new site in Discovery service, it requires admin scope
+func (c *Client) AddSite(ctx context.Context, site Site) error {
+	var response Response
+	rurl := fmt.Sprintf("%s/site", c.Services.DiscoveryURL)
+	return c.call(ctx, http.MethodPost, rurl, site, ScopeAdmin, &response)
+}
+
+// DeleteSite removes site from Discovery service, it requires admin scope
+func (c *Client) DeleteSite(ctx context.Context, name string) error {
+	var response Response
+	rurl := fmt.Sprintf("%s/site/%s", c.Services.DiscoveryURL, url.PathEscape(name))
+	return c.call(ctx, http.MethodDelete, rurl, nil, ScopeAdmin, &response)
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// StorageRecord represents Storage record returned by datamanagement service
+type StorageRecord struct {
+	Status string `json:"status"`
+	Data   any    `json:"data"`
+}
+
+// UploadRecord represents Storage record returned by datamanagement service
+type UploadRecord struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Msg    string `json:"msg"`
+	Object any    `json:"object"`
+}
+
+// helper function to send storage request to DataManagement service
+func (c *Client) storage(ctx context.Context, method, bucket string) (StorageRecord, error) {
+	var rec StorageRecord
+	rurl := fmt.Sprintf("%s/storage/%s", c.Services.DataManagementURL, bucket)
+	err := c.call(ctx, method, rurl, nil, "", &rec)
+	return rec, err
+}
+
+// ListBucket returns content of given site or site/bucket on s3 storage
+func (c *Client) ListBucket(ctx context.Context, bucket string) (StorageRecord, error) {
+	return c.storage(ctx, http.MethodGet, bucket)
+}
+
+// CreateBucket creates new site/bucket on s3 storage
+func (c *Client) CreateBucket(ctx context.Context, bucket string) (StorageRecord, error) {
+	return c.storage(ctx, http.MethodPost, bucket)
+}
+
+// DeleteBucket deletes given site/bucket on s3 storage
+func (c *Client) DeleteBucket(ctx context.Context, bucket string) (StorageRecord, error) {
+	return c.storage(ctx, http.MethodDelete, bucket)
+}
+
+// Upload uploads given file to site/bucket on s3 storage, the object name is
+// the base name of the file
+func (c *Client) Upload(ctx context.Context, bucket, fname string) (UploadRecord, error) {
+	var rec UploadRecord
+	file, err := os.Open(fname)
+	if err != nil {
+		return rec, err
+	}
+	defer file.Close()
+
+	// send POST request to DataManagement service with file data content
+	// see https://stackoverflow.com/questions/20205796/post-data-using-the-content-type-multipart-form-data
+	/*
+	   ```
+	    curl -X POST http://localhost:8340/storage/cornell/s3-bucket/archive.zip \
+	     -F "file=@/path/test.zip" \
+	     -H "Content-Type: multipart/form-data"
+	   ```
+	*/
+	// TODO: we may need buffer stream to reduce RAM utilization
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	fw, err := w.CreateFormFile("file", file.Name())
+	if err != nil {
+		return rec, err
+	}
+	if _, err := io.Copy(fw, file); err != nil {
+		return rec, err
+	}
+	if err := w.Close(); err != nil {
+		return rec, err
+	}
+
+	name := filepath.Base(fname)
+	rurl := fmt.Sprintf("%s/storage/%s/%s", c.Services.DataManagementURL, bucket, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rurl, &buf)
+	if err != nil {
+		return rec, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	err = c.decode(ctx, req, "", &rec)
+	return rec, err
+}
@@ -0,0 +1,306 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTimeout defines default connect and read timeout of HTTP requests
+const DefaultTimeout = 30 * time.Second
+
+// DefaultRetries defines default number of retries of failed HTTP requests
+const DefaultRetries = 3
+
+// NewTransport creates HTTP transport with given connect and read timeout,
+// the read timeout limits time spent waiting for response headers while
+// IdleTimeoutTransport limits time spent waiting for response body
+func NewTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// NewHTTPClient creates HTTP client with given timeout which retries failed
+// requests given number of times
+func NewHTTPClient(timeout time.Duration, retries int) *http.Client {
+	return &http.Client{
+		Transport: &RetryTransport{
+			Base:    &IdleTimeoutTransport{Base: NewTransport(timeout), Timeout: timeout},
+			Retries: retries,
+		},
+	}
+}
+
+// errIdleTimeout is returned when response body does not receive any data
+// within timeout of IdleTimeoutTransport
+var errIdleTimeout = fmt.Errorf("no response data received within timeout: %w", os.ErrDeadlineExceeded)
+
+// IdleTimeoutTransport aborts requests whose response body does not receive
+// any data within Timeout, e.g. when service stalls while sending the body.
+// The http.Transport ResponseHeaderTimeout only covers response headers.
+type IdleTimeoutTransport struct {
+	Base    http.RoundTripper // underlying transport, http.DefaultTransport if nil
+	Timeout time.Duration     // maximum time between reads of response body, no limit if zero
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *IdleTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Timeout <= 0 {
+		return base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+	body := &idleTimeoutBody{body: resp.Body, cancel: cancel, timeout: t.Timeout, req: req}
+	body.timer = time.AfterFunc(t.Timeout, func() {
+		body.expired.Store(true)
+		cancel()
+	})
+	resp.Body = body
+	return resp, nil
+}
+
+// idleTimeoutBody cancels request when its reads stall longer than timeout
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	cancel  context.CancelFunc
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+	req     *http.Request
+}
+
+// Read implements io.Reader interface
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.expired.Load() {
+		return n, &url.Error{Op: b.req.Method, URL: b.req.URL.String(), Err: errIdleTimeout}
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+// Close implements io.Closer interface
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
+
+// RetryTransport retries failed HTTP requests with exponential backoff and
+// jitter. Requests rejected with 429 or 503 status are retried regardless of
+// their method since server did not process them, while network errors and
+// other 5xx statuses are only retried for idempotent requests.
+type RetryTransport struct {
+	Base       http.RoundTripper // underlying transport, http.DefaultTransport if nil
+	Retries    int               // maximum number of retries
+	MinBackoff time.Duration     // initial backoff, 500ms if zero
+	MaxBackoff time.Duration     // maximum backoff, 30s if zero
+}
+
+// helper function to check if request method is idempotent
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// helper function to check if request should be retried
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return req.Context().Err() == nil && idempotent(req.Method)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError && idempotent(req.Method)
+}
+
+// helper function to parse Retry-After header given in seconds or HTTP date
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	val := resp.Header.Get("Retry-After")
+	if val == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(val); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// helper function to calculate backoff of given attempt, it uses server
+// Retry-After header if present and exponential backoff with jitter otherwise
+func (t *RetryTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	minBackoff, maxBackoff := t.MinBackoff, t.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = 500 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	if d := retryAfter(resp); d > 0 {
+		if d > maxBackoff {
+			return maxBackoff
+		}
+		return d
+	}
+	d := minBackoff << attempt
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if attempt >= t.Retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+		// the request body can only be sent again if it can be rewound
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// TransportOptions represents TLS and proxy options of HTTP transport
+type TransportOptions struct {
+	CABundle           string // PEM file with additional CA certificates
+	ClientCert         string // PEM file with client certificate for mutual TLS
+	ClientKey          string // PEM file with client key, defaults to ClientCert
+	ProxyURL           string // proxy URL, proxy environment variables are used if empty
+	InsecureSkipVerify bool   // skip verification of server certificates
+}
+
+// helper function to expand leading ~ of file name into user home directory
+func expandHome(fname string) (string, error) {
+	if fname != "~" && !strings.HasPrefix(fname, "~/") {
+		return fname, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fname, err
+	}
+	return filepath.Join(home, fname[1:]), nil
+}
+
+// Configure applies TLS and proxy options to given HTTP transport, leading ~
+// of CA bundle, client certificate and key file names is expanded into user
+// home directory
+func (o TransportOptions) Configure(t *http.Transport) error {
+	for _, fname := range []*string{&o.CABundle, &o.ClientCert, &o.ClientKey} {
+		var err error
+		if *fname, err = expandHome(*fname); err != nil {
+			return err
+		}
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if o.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no PEM certificates found in CA bundle %s", o.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if o.ClientCert != "" {
+		key := o.ClientKey
+		if key == "" {
+			key = o.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, key)
+		if err != nil {
+			return fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if o.ClientKey != "" {
+		return errors.New("client key is given without client certificate")
+	}
+	t.TLSClientConfig = tlsConfig
+	if o.ProxyURL != "" {
+		u, err := url.Parse(o.ProxyURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL %s", o.ProxyURL)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// helper function to create RetryTransport with short backoff for tests
+func testRetryTransport(retries int) *RetryTransport {
+	return &RetryTransport{
+		Retries:    retries,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	}
+}
+
+// TestRetryTransport tests which failures are retried by RetryTransport
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int // statuses returned by consecutive attempts, last one repeats
+		want     int   // expected final status
+		attempts int32 // expected number of attempts
+	}{
+		{"success", http.MethodGet, []int{200}, 200, 1},
+		{"GET 503 then success", http.MethodGet, []int{503, 200}, 200, 2},
+		{"GET 500 then success", http.MethodGet, []int{500, 200}, 200, 2},
+		{"POST 429 then success", http.MethodPost, []int{429, 200}, 200, 2},
+		{"POST 503 then success", http.MethodPost, []int{503, 200}, 200, 2},
+		{"POST 500 not retried", http.MethodPost, []int{500, 200}, 500, 1},
+		{"PUT 502 then success", http.MethodPut, []int{502, 200}, 200, 2},
+		{"GET 404 not retried", http.MethodGet, []int{404, 200}, 404, 1},
+		{"GET retries exhausted", http.MethodGet, []int{503}, 503, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				idx := int(n) - 1
+				if idx >= len(tt.statuses) {
+					idx = len(tt.statuses) - 1
+				}
+				w.WriteHeader(tt.statuses[idx])
+			}))
+			defer srv.Close()
+			client := &http.Client{Transport: testRetryTransport(2)}
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader("data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("%d attempts, want %d", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+// TestRetryTransportNetworkError tests that network errors are only retried
+// for idempotent requests
+func TestRetryTransportNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rurl := srv.URL
+	srv.Close()
+	tests := []struct {
+		method   string
+		attempts int
+	}{
+		{http.MethodGet, 3},
+		{http.MethodPost, 1},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		rt := testRetryTransport(2)
+		rt.Base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		req, _ := http.NewRequest(tt.method, rurl, nil)
+		if _, err := rt.RoundTrip(req); err == nil {
+			t.Errorf("%s: expected error", tt.method)
+		}
+		if attempts != tt.attempts {
+			t.Errorf("%s: %d attempts, want %d", tt.method, attempts, tt.attempts)
+		}
+	}
+}
+
+// roundTripFunc is an adapter to use ordinary function as http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper interface
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TestRetryTransportRewindsBody tests that request body is sent again on retry
+func TestRetryTransportRewindsBody(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: testRetryTransport(2)}
+	resp, err := client.Post(srv.URL, "text/plain", bytes.NewBufferString("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(bodies) != 2 || bodies[0] != "payload" || bodies[1] != "payload" {
+		t.Errorf("unexpected request bodies %q", bodies)
+	}
+}
+
+// TestRetryTransportNoGetBody tests that request whose body can't be rewound
+// is not retried
+func TestRetryTransportNoGetBody(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPut, srv.URL, io.NopCloser(strings.NewReader("payload")))
+	resp, err := (&http.Client{Transport: testRetryTransport(2)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if attempts != 1 {
+		t.Errorf("%d attempts, want 1", attempts)
+	}
+}
+
+// TestRetryAfter tests parsing of Retry-After header and its use as backoff
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"2", 2 * time.Second},
+		{"0", 0},
+		{"invalid", 0},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.header != "" {
+			resp.Header.Set("Retry-After", tt.header)
+		}
+		got := retryAfter(resp)
+		if got > tt.want || got < tt.want-2*time.Second {
+			t.Errorf("Retry-After %q: got %s, want %s", tt.header, got, tt.want)
+		}
+	}
+	rt := &RetryTransport{MaxBackoff: time.Minute}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	if d := rt.backoff(0, resp); d != 7*time.Second {
+		t.Errorf("backoff %s, want Retry-After 7s", d)
+	}
+	rt.MaxBackoff = 3 * time.Second
+	if d := rt.backoff(0, resp); d != 3*time.Second {
+		t.Errorf("backoff %s, want it capped at 3s", d)
+	}
+}
+
+// TestBackoff tests exponential backoff with jitter
+func TestBackoff(t *testing.T) {
+	rt := &RetryTransport{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		d := rt.backoff(attempt, nil)
+		if d < max/2 || d > max {
+			t.Errorf("attempt %d: backoff %s not in [%s, %s]", attempt, d, max/2, max)
+		}
+	}
+}
+
+// TestIdleTimeoutTransport tests that stalled response body is aborted
+func TestIdleTimeoutTransport(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	client := &http.Client{Transport: &IdleTimeoutTransport{Timeout: 100 * time.Millisecond}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	start := time.Now()
+	data, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("unexpected error %v", err)
+	}
+	if string(data) != "partial" {
+		t.Errorf("unexpected body %q", data)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stalled body was aborted after %s", elapsed)
+	}
+}
+
+// TestIdleTimeoutTransportSlowBody tests that body which keeps sending data
+// is not aborted even if it takes longer than timeout in total
+func TestIdleTimeoutTransportSlowBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &IdleTimeoutTransport{Timeout: 100 * time.Millisecond}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil || string(data) != "xxxxx" {
+		t.Errorf("unexpected body %q, error %v", data, err)
+	}
+}
+
+// helper function to write self-signed certificate and its key as PEM files
+func writeTestCert(t *testing.T, dir string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "orecast test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	os.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0600)
+	os.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0600)
+	os.WriteFile(filepath.Join(dir, "both.pem"), append(certPEM, keyPEM...), 0600)
+}
+
+// TestTransportOptionsHome tests that file names starting with ~ are
+// resolved in user home directory
+func TestTransportOptionsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeTestCert(t, home)
+	tests := []struct {
+		name    string
+		opts    TransportOptions
+		wantErr bool
+	}{
+		{"home CA bundle", TransportOptions{CABundle: "~/cert.pem"}, false},
+		{"home client cert and key", TransportOptions{ClientCert: "~/cert.pem", ClientKey: "~/key.pem"}, false},
+		{"home client cert with key", TransportOptions{ClientCert: "~/both.pem"}, false},
+		{"absolute client cert", TransportOptions{ClientCert: filepath.Join(home, "cert.pem"), ClientKey: filepath.Join(home, "key.pem")}, false},
+		{"missing home file", TransportOptions{CABundle: "~/missing.pem"}, true},
+		{"tilde inside name is kept", TransportOptions{CABundle: filepath.Join(home, "~/cert.pem")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTransport(time.Second)
+			err := tt.opts.Configure(tr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err == nil && tt.opts.ClientCert != "" && len(tr.TLSClientConfig.Certificates) != 1 {
+				t.Error("client certificate was not loaded")
+			}
+		})
+	}
+}
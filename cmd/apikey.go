@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	oreClient "github.com/OreCast/client/client"
+	"github.com/spf13/cobra"
+)
+
+// apiKeyGrantType represents grant type used to exchange API key for access token
+const apiKeyGrantType = "api_key"
+
+// APIKey represents API key record managed by Authz service
+type APIKey = oreClient.APIKey
+
+// helper function to return configured API key, it can be provided via
+// client.api_key configuration, ORECAST_API_KEY environment or --api-key flag
+func apiKey() string {
+	return _clientConfig.APIKey
+}
+
+// helper function to obtain access token of given scope using configured API key
+func apiKeySession(scope string) (Session, error) {
+	form := url.Values{}
+	form.Set("grant_type", apiKeyGrantType)
+	form.Set("api_key", apiKey())
+	form.Set("scope", scope)
+	token, err := tokenRequest(form)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := validateToken(token.AccessToken); err != nil {
+		return Session{}, err
+	}
+	session := Session{
+		Login:        tokenLogin(token.AccessToken),
+		Scope:        scope,
+		Grant:        apiKeyGrantType,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+	return storeSession(session), nil
+}
+
+// helper function to provide usage of apikey option
+func apikeyUsage() {
+	fmt.Println("orecast apikey <create|ls|revoke> [value] [--scopes read,write] [--expires 720h]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# create new API key with read scope which expires in 30 days:")
+	fmt.Println("orecast apikey create ci-job --scopes read --expires 720h")
+	fmt.Println("\n# list existing API keys:")
+	fmt.Println("orecast apikey ls")
+	fmt.Println("\n# revoke API key:")
+	fmt.Println("orecast apikey revoke 123xyz")
+}
+
+// helper function to create new API key
+func apikeyCreate(args []string, scopes string, expires time.Duration) {
+	if len(args) != 2 {
+		exitWithUsage(apikeyUsage)
+	}
+	key := APIKey{Name: args[1]}
+	for _, s := range strings.Split(scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			key.Scopes = append(key.Scopes, s)
+		}
+	}
+	if expires > 0 {
+		key.Expires = time.Now().Add(expires).Unix()
+	}
+	k, err := orecastClient().CreateAPIKey(cmdContext(), key)
+	if err != nil {
+		exitError(err)
+	}
+	printAPIKey(k)
+	// the secret is intentionally printed here, it is shown only once
+	fmt.Printf("Secret     : %s\n", k.Secret)
+	fmt.Println("\nPlease store the secret now, it will not be shown again.")
+	fmt.Println("Use it via ORECAST_API_KEY environment variable or client.api_key configuration.")
+}
+
+// helper function to list API keys
+func apikeyList(args []string) {
+	keys, err := orecastClient().ListAPIKeys(cmdContext())
+	if err != nil {
+		exitError(err)
+	}
+	for _, k := range keys {
+		fmt.Println("---")
+		printAPIKey(k)
+	}
+}
+
+// helper function to revoke API key
+func apikeyRevoke(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(apikeyUsage)
+	}
+	kid := args[1]
+	if err := orecastClient().RevokeAPIKey(cmdContext(), kid); err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: API key %s was successfully revoked\n", kid)
+}
+
+// helper function to print API key attributes
+func printAPIKey(k APIKey) {
+	fmt.Printf("ID         : %s\n", k.ID)
+	fmt.Printf("Name       : %s\n", k.Name)
+	fmt.Printf("Scopes     : %s\n", strings.Join(k.Scopes, ","))
+	if k.Created > 0 {
+		fmt.Printf("Created    : %s\n", time.Unix(k.Created, 0).Format(time.RFC3339))
+	}
+	if k.Expires > 0 {
+		fmt.Printf("Expires    : %s\n", time.Unix(k.Expires, 0).Format(time.RFC3339))
+	} else {
+		fmt.Printf("Expires    : never\n")
+	}
+}
+
+func apikeyCommand() *cobra.Command {
+	var scopes string
+	var expires time.Duration
+	cmd := &cobra.Command{
+		Use:   "apikey",
+		Short: "OreCast API key command",
+		Long: `OreCast API key command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				apikeyUsage()
+			} else if args[0] == "create" {
+				apikeyCreate(args, scopes, expires)
+			} else if args[0] == "ls" {
+				apikeyList(args)
+			} else if args[0] == "revoke" {
+				apikeyRevoke(args)
+			} else {
+				unsupportedOptions(args, apikeyUsage)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&scopes, "scopes", scopeRead, "comma separated list of API key scopes")
+	cmd.Flags().DurationVar(&expires, "expires", 0, "API key lifetime, e.g. 720h, by default key does not expire")
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		apikeyUsage()
+		return nil
+	})
+	return cmd
+}
@@ -2,161 +2,315 @@ package cmd
 
 import (
 	"bufio"
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
+	oreClient "github.com/OreCast/client/client"
 	authz "github.com/OreCast/common/authz"
-	jwt "github.com/golang-jwt/jwt/v4"
 	"github.com/spf13/cobra"
 	term "golang.org/x/term"
 )
 
 // User represents structure used by users DB in Authz service to handle incoming requests
-type User struct {
-	Login    string
-	Password string
+type User = oreClient.User
+
+// token scopes requested from Authz service
+const (
+	scopeRead  = oreClient.ScopeRead  // list OreCast records
+	scopeWrite = oreClient.ScopeWrite // add or remove OreCast records
+	scopeAdmin = oreClient.ScopeAdmin // manage OreCast sites
+)
+
+// login flows recorded in cached sessions
+const (
+	passwordGrant = "password"
+	deviceGrant   = "device"
+	browserGrant  = "browser"
+)
+
+// TokenResponse represents response of Authz token endpoint, the refresh
+// token is optional and only issued by Authz services which support it
+type TokenResponse struct {
+	authz.Token
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
-// helper function to get orecast token
-func getToken(login, pass string) (string, error) {
-	var token string
-	// make a call to Authz service to check for a user
-	rurl := fmt.Sprintf(
-		"%s/oauth/authorize?client_id=%s&response_type=code",
-		_oreConfig.Services.AuthzURL,
-		_oreConfig.Authz.ClientId)
-	if verbose > 0 {
-		fmt.Println("HTTP GET", rurl)
+// OAuthError represents error response of Authz OAuth2 endpoints
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Error implements error interface
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
 	}
+	return e.Code
+}
+
+// helper function to get orecast token of given scope
+func getToken(login, pass, scope string) (TokenResponse, error) {
+	var token TokenResponse
+	// make a call to Authz service to check for a user
+	addSecret(pass)
 	user := User{Login: login, Password: pass}
-	data, err := json.Marshal(user)
-	if err != nil {
+	if err := authorizeUser(user); err != nil {
 		return token, err
 	}
-	resp, err := http.Post(rurl, "application/json", bytes.NewBuffer(data))
+
+	// make request to get authz token
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", scope)
+	aToken, err := tokenRequest(form)
 	if err != nil {
 		return token, err
 	}
-	defer resp.Body.Close()
-	data, err = io.ReadAll(resp.Body)
+	reqToken := aToken.AccessToken
 	if verbose > 1 {
-		fmt.Println("## authorize response", string(data))
+		debugPrintln("## request token", reqToken)
 	}
-	var response authz.Response
-	err = json.Unmarshal(data, &response)
-	if err != nil {
+
+	if err := validateToken(reqToken); err != nil {
 		return token, err
 	}
-	if response.Status != "ok" {
-		msg := fmt.Sprintf("No user %s found in Authz service", user)
-		return token, errors.New(msg)
-	}
-
-	// make request to get authz token
-
-	var aToken authz.Token
-	rurl = fmt.Sprintf(
-		"%s/oauth/token?client_id=%s&client_secret=%s&grant_type=client_credentials&scope=read",
-		_oreConfig.Services.AuthzURL,
-		_oreConfig.Authz.ClientId,
-		_oreConfig.Authz.ClientSecret)
+	return aToken, nil
+}
 
+// helper function to request token from Authz token endpoint, the client
+// credentials are sent via HTTP Basic Authorization header and grant parameters
+// via form-encoded POST body as required by OAuth2
+func tokenRequest(form url.Values) (TokenResponse, error) {
+	var aToken TokenResponse
+	rurl := fmt.Sprintf("%s/oauth/token", _oreConfig.Services.AuthzURL)
 	if verbose > 0 {
-		fmt.Println("HTTP GET", rurl)
-	}
-
-	resp, err = http.Get(rurl)
-	defer resp.Body.Close()
-	data, err = io.ReadAll(resp.Body)
-	if verbose > 1 {
-		fmt.Println("## token data", string(data))
+		debugPrintln("HTTP POST", rurl)
 	}
+	req, err := http.NewRequestWithContext(cmdContext(), "POST", rurl, strings.NewReader(form.Encode()))
 	if err != nil {
-		return token, err
+		return aToken, err
 	}
-	err = json.Unmarshal(data, &aToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(_oreConfig.Authz.ClientId), url.QueryEscape(_oreConfig.Authz.ClientSecret))
+	resp, err := httpClient().Do(req)
 	if err != nil {
-		return token, err
+		return aToken, fmt.Errorf("%w: %w", oreClient.ErrNetwork, err)
 	}
-	reqToken := aToken.AccessToken
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
 	if verbose > 1 {
-		fmt.Println("## request token", reqToken)
+		debugPrintln("## token data", string(data))
 	}
-
-	// validate our token
-	var jwtKey = []byte(_oreConfig.Authz.ClientId)
-	claims := &authz.Claims{}
-	tkn, err := jwt.ParseWithClaims(reqToken, claims, func(token *jwt.Token) (any, error) {
-		return jwtKey, nil
-	})
 	if err != nil {
-		if err == jwt.ErrSignatureInvalid {
-			return token, errors.New("invalid token signature")
+		return aToken, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oerr OAuthError
+		if err := json.Unmarshal(data, &oerr); err == nil && oerr.Code != "" {
+			return aToken, &oerr
 		}
-		return token, err
+		return aToken, oreClient.CheckResponse(resp, data)
 	}
-	if !tkn.Valid {
-		return token, errors.New("invalid token validity")
+	err = json.Unmarshal(data, &aToken)
+	if err != nil {
+		return aToken, err
 	}
-	return reqToken, nil
+	addSecret(aToken.AccessToken)
+	addSecret(aToken.RefreshToken)
+	return aToken, nil
 }
 
+// stdinReader is shared among prompts to not lose buffered input
+// when stdin is not a terminal, e.g. piped input in CI jobs
+var stdinReader = bufio.NewReader(os.Stdin)
+
 // helper function to get user input
 func inputPrompt(label string) string {
 	var s string
-	r := bufio.NewReader(os.Stdin)
 	for {
 		fmt.Fprint(os.Stderr, label+" ")
-		s, _ = r.ReadString('\n')
-		if s != "" {
+		line, err := stdinReader.ReadString('\n')
+		s = strings.TrimSpace(line)
+		if s != "" || err != nil {
 			break
 		}
 	}
-	return strings.TrimSpace(s)
+	return s
 }
 
 // helper function to get user password
 func passwordPrompt(label string) string {
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return inputPrompt(label)
+	}
 	var s string
 	for {
 		fmt.Fprint(os.Stderr, label+" ")
-		pw, _ := term.ReadPassword(int(syscall.Stdin))
+		pw, err := term.ReadPassword(int(syscall.Stdin))
 		s = string(pw)
-		if s != "" {
+		if s != "" || err != nil {
 			break
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(os.Stderr)
 	return s
 }
 
-// helper function to get access token
-func accessToken() (string, error) {
-	user := inputPrompt("OreCast username:")
-	pass := passwordPrompt("OreCast password:")
-	return getToken(user, pass)
+// helper function to obtain new token of given scope from Authz service and
+// store it in token cache
+func newSession(scope string) (Session, error) {
+	user, pass, err := credentials()
+	if err != nil {
+		return Session{}, err
+	}
+	token, err := getToken(user, pass, scope)
+	if err != nil {
+		return Session{}, err
+	}
+	session := Session{
+		Login:        user,
+		Scope:        scope,
+		Grant:        passwordGrant,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+	return storeSession(session), nil
+}
+
+// helper function to store session in token cache, it returns session with
+// filled attributes or original session when it can't be cached
+func storeSession(session Session) Session {
+	s, err := saveSession(session)
+	if err != nil {
+		fmt.Println("WARNING: unable to cache token", err)
+		return session
+	}
+	return s
+}
+
+// helper function to check if user may be prompted for password to obtain
+// token of given scope, it returns an error when user has usable session of
+// device or browser login since the user may not have a password, e.g. on
+// headless machines or with federated login. Expired sessions which can't be
+// refreshed do not prevent password login.
+func checkPasswordFallback(scope string) error {
+	sessions, err := userSessions()
+	if errors.Is(err, errNoSession) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read token cache: %w", err)
+	}
+	for _, s := range sessions {
+		if s.Grant != deviceGrant && s.Grant != browserGrant {
+			continue
+		}
+		if s.Valid() || s.Refreshable() {
+			return fmt.Errorf("%w: no token with %s scope, run orecast login --%s --scope %s",
+				oreClient.ErrAuth, scope, s.Grant, scope)
+		}
+	}
+	return nil
+}
+
+// helper function to get access token of given scope, it reuses token of
+// given or broader scope from token cache, refreshes it when it is close to
+// expiration and otherwise exchanges configured API key, obtains the scope with
+// cached refresh token or prompts for user credentials
+func accessToken(scope string) (string, error) {
+	if replaying() {
+		// recorded tokens are redacted and requests are not sent to OreCast services
+		return redactedValue, nil
+	}
+	if token := os.Getenv("ORECAST_TOKEN"); token != "" {
+		addSecret(token)
+		if verbose > 0 {
+			fmt.Println("use token from ORECAST_TOKEN environment")
+		}
+		return token, nil
+	}
+	for _, s := range coveringSessions(scope) {
+		if s.RefreshToken != "" && s.ExpiresWithin(refreshWindow()) {
+			session, err := refreshSession(s)
+			if err == nil {
+				return session.AccessToken, nil
+			}
+			fmt.Println("WARNING: unable to refresh token", redact(err.Error()))
+		}
+		if s.Valid() {
+			if verbose > 0 {
+				fmt.Println("use cached token with", s.Scope, "scope, expires", time.Unix(s.Expires, 0))
+			}
+			return s.AccessToken, nil
+		}
+	}
+	if apiKey() != "" {
+		session, err := apiKeySession(scope)
+		return session.AccessToken, err
+	}
+	if session, err := scopeSession(scope); err == nil {
+		return session.AccessToken, nil
+	}
+	if err := checkPasswordFallback(scope); err != nil {
+		return "", err
+	}
+	session, err := newSession(scope)
+	return session.AccessToken, err
+}
+
+// helper function to provide usage of token option
+func tokenUsage() {
+	fmt.Println("orecast token [inspect] [token|-] [--verify] [--json]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# obtain access token:")
+	fmt.Println("orecast token")
+	fmt.Println("\n# inspect token of current session:")
+	fmt.Println("orecast token inspect")
+	fmt.Println("\n# inspect given token and verify its signature:")
+	fmt.Println("orecast token inspect eyJhbGciOi... --verify")
+	fmt.Println("\n# inspect token from stdin and print it in JSON format:")
+	fmt.Println("echo eyJhbGciOi... | orecast token inspect - --json")
 }
 
 func authCommand() *cobra.Command {
+	var verify, jsonOutput bool
+	var scope string
 	cmd := &cobra.Command{
 		Use:   "token",
 		Short: "OreCast token command",
 		Long: `OreCast token command
                 Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
-			if token, err := accessToken(); err == nil {
-				fmt.Println(token)
+			if len(args) == 0 {
+				if token, err := accessToken(scope); err == nil {
+					fmt.Println(token)
+				} else {
+					exitError(err)
+				}
+			} else if args[0] == "inspect" {
+				tokenInspect(args, verify, jsonOutput)
 			} else {
-				fmt.Println("ERROR", err)
+				unsupportedOptions(args, tokenUsage)
 			}
 		},
 	}
+	cmd.Flags().StringVar(&scope, "scope", scopeRead, "token scope: read, write or admin")
+	cmd.Flags().BoolVar(&verify, "verify", false, "verify token signature")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print token information in JSON format")
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		tokenUsage()
+		return nil
+	})
 	return cmd
 }
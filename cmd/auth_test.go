@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oreClient "github.com/OreCast/client/client"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// helper function to create unsigned test token which expires after given duration
+func expiringToken(t *testing.T, d time.Duration) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "bob",
+		"exp": time.Now().Add(d).Unix(),
+	}).SignedString([]byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestCheckPasswordFallback tests when user may be prompted for password
+func TestCheckPasswordFallback(t *testing.T) {
+	valid := expiringToken(t, time.Hour)
+	expired := expiringToken(t, -time.Hour)
+	tests := []struct {
+		name    string
+		session *Session // cached session with read scope, nil for empty cache
+		allowed bool
+	}{
+		{"no cached session", nil, true},
+		{"password session", &Session{Grant: passwordGrant, AccessToken: valid}, true},
+		{"valid device session", &Session{Grant: deviceGrant, AccessToken: valid}, false},
+		{"valid browser session", &Session{Grant: browserGrant, AccessToken: valid}, false},
+		{"expired device session", &Session{Grant: deviceGrant, AccessToken: expired}, true},
+		{"refreshable device session", &Session{Grant: deviceGrant, AccessToken: expired, RefreshToken: valid}, false},
+		{"opaque refresh token", &Session{Grant: browserGrant, AccessToken: expired, RefreshToken: "opaque"}, false},
+		{"expired refresh token", &Session{Grant: deviceGrant, AccessToken: expired, RefreshToken: expired}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVerifyConfig(t, ClientConfig{}, "client_id")
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			if tt.session != nil {
+				s := *tt.session
+				s.Scope = scopeRead
+				s.Expires, _ = tokenExpires(s.AccessToken)
+				if err := writeSessions(map[string]Session{sessionKey(scopeRead): s}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := checkPasswordFallback(scopeWrite)
+			if tt.allowed && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, oreClient.ErrAuth) {
+				t.Errorf("expected ErrAuth, got %v", err)
+			}
+		})
+	}
+}
+
+// TestCheckPasswordFallbackBrokenCache tests that token cache read error is reported
+func TestCheckPasswordFallbackBrokenCache(t *testing.T) {
+	setVerifyConfig(t, ClientConfig{}, "client_id")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	fname, err := sessionFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(fname), 0700)
+	if err := os.WriteFile(fname, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPasswordFallback(scopeRead); err == nil || errors.Is(err, oreClient.ErrAuth) {
+		t.Errorf("expected token cache error, got %v", err)
+	}
+}
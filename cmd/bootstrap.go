@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	oreConfig "github.com/OreCast/common/config"
+	"github.com/spf13/viper"
+)
+
+// defaultDiscoveryTTL defines how long discovered service URLs are cached
+const defaultDiscoveryTTL = time.Hour
+
+// discoveryTimeout defines how long we wait for discovery document
+const discoveryTimeout = 5 * time.Second
+
+// DiscoveryDocument represents service endpoints published by Discovery service
+type DiscoveryDocument struct {
+	FrontendURL        string `json:"frontend_url,omitempty"`
+	MetaDataURL        string `json:"metadata_url,omitempty"`
+	DataManagementURL  string `json:"datamanagement_url,omitempty"`
+	DataBookkeepingURL string `json:"databookkeeping_url,omitempty"`
+	AuthzURL           string `json:"authz_url,omitempty"`
+}
+
+// configuration keys obtained from Discovery service
+var _discovered = make(map[string]bool)
+
+// discoveryCacheEntry represents cached discovery document
+type discoveryCacheEntry struct {
+	Fetched  int64             `json:"fetched"`
+	Document DiscoveryDocument `json:"document"`
+}
+
+// helper function to check if client should bootstrap service URLs from
+// Discovery service, it is enabled explicitly via client.bootstrap or
+// implicitly when Discovery URL is the only configured service URL
+func bootstrapEnabled(config *oreConfig.OreCastConfig) bool {
+	s := config.Services
+	if s.DiscoveryURL == "" {
+		return false
+	}
+	if _clientConfig.Bootstrap {
+		return true
+	}
+	return s.MetaDataURL == "" && s.DataManagementURL == "" &&
+		s.DataBookkeepingURL == "" && s.AuthzURL == ""
+}
+
+// helper function to return configured discovery cache TTL
+func discoveryTTL() time.Duration {
+	if _clientConfig.DiscoveryTTL > 0 {
+		return _clientConfig.DiscoveryTTL
+	}
+	return defaultDiscoveryTTL
+}
+
+// helper function to return location of discovery cache file
+func discoveryFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orecast", "discovery.json"), nil
+}
+
+// helper function to read discovery cache
+func readDiscoveryCache() map[string]discoveryCacheEntry {
+	cache := make(map[string]discoveryCacheEntry)
+	fname, err := discoveryFile()
+	if err != nil {
+		return cache
+	}
+	if data, err := os.ReadFile(fname); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// helper function to write discovery cache
+func writeDiscoveryCache(cache map[string]discoveryCacheEntry) error {
+	fname, err := discoveryFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fname, data, 0600)
+}
+
+// helper function to fetch discovery document from Discovery service
+func fetchDiscoveryDocument(discoveryURL string) (DiscoveryDocument, error) {
+	var doc DiscoveryDocument
+	rurl := fmt.Sprintf("%s/services", discoveryURL)
+	if verbose > 0 {
+		debugPrintln("HTTP GET", rurl)
+	}
+	// discovery runs before every command, do not retry unavailable service
+	ctx, cancel := context.WithTimeout(cmdContext(), discoveryTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rurl, nil)
+	if err != nil {
+		return doc, err
+	}
+	client, err := newHTTPClient(discoveryTimeout, 0)
+	if err != nil {
+		return doc, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return doc, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return doc, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return doc, fmt.Errorf("unable to fetch discovery document from %s, status %s", rurl, resp.Status)
+	}
+	err = json.Unmarshal(data, &doc)
+	return doc, err
+}
+
+// helper function to obtain discovery document, it uses cached document
+// within its TTL and falls back to expired cached document when Discovery
+// service is unavailable
+func discoveryDocument(discoveryURL string) (DiscoveryDocument, error) {
+	cache := readDiscoveryCache()
+	entry, cached := cache[discoveryURL]
+	if cached && time.Since(time.Unix(entry.Fetched, 0)) < discoveryTTL() {
+		return entry.Document, nil
+	}
+	doc, err := fetchDiscoveryDocument(discoveryURL)
+	if err != nil {
+		if cached {
+			if verbose > 0 {
+				fmt.Println("WARNING: use expired discovery document,", err)
+			}
+			return entry.Document, nil
+		}
+		return doc, err
+	}
+	cache[discoveryURL] = discoveryCacheEntry{Fetched: time.Now().Unix(), Document: doc}
+	if err := writeDiscoveryCache(cache); err != nil && verbose > 0 {
+		fmt.Println("WARNING: unable to cache discovery document", err)
+	}
+	return doc, nil
+}
+
+// helper function to set service URL from discovery document unless it is
+// explicitly overridden by flag or environment variable
+func discoveredURL(key, value string, field *string) {
+	if value == "" {
+		return
+	}
+	for _, o := range configOverrides {
+		if o.Key == key {
+			src := overrideSource(o)
+			if strings.HasPrefix(src, "flag") || strings.HasPrefix(src, "env") {
+				return
+			}
+		}
+	}
+	*field = value
+	viper.Set(key, value)
+	_discovered[key] = true
+}
+
+// helper function to bootstrap service URLs from Discovery service, when
+// discovery is unavailable the configured values are kept
+func bootstrapServices(config *oreConfig.OreCastConfig) {
+	if !bootstrapEnabled(config) {
+		return
+	}
+	doc, err := discoveryDocument(config.Services.DiscoveryURL)
+	if err != nil {
+		if verbose > 0 {
+			fmt.Println("WARNING: discovery is unavailable, use configured service URLs,", redact(err.Error()))
+		}
+		return
+	}
+	s := &config.Services
+	discoveredURL("services.frontend_url", doc.FrontendURL, &s.FrontendURL)
+	discoveredURL("services.metadata_url", doc.MetaDataURL, &s.MetaDataURL)
+	discoveredURL("services.datamanagement_url", doc.DataManagementURL, &s.DataManagementURL)
+	discoveredURL("services.databookkeeping_url", doc.DataBookkeepingURL, &s.DataBookkeepingURL)
+	discoveredURL("services.authz_url", doc.AuthzURL, &s.AuthzURL)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	oreClient "github.com/OreCast/client/client"
+)
+
+// Used for record and replay flags.
+var (
+	recordFile string
+	replayFile string
+)
+
+// recorder of HTTP interactions in record mode
+var _recorder *oreClient.Recorder
+
+// replayer of HTTP interactions in replay mode
+var _replayer *oreClient.Replayer
+
+// helper function to setup record or replay mode of HTTP requests
+func setupCassette() error {
+	if recordFile != "" && replayFile != "" {
+		return errors.New("--record and --replay flags are mutually exclusive")
+	}
+	if recordFile != "" {
+		_recorder = &oreClient.Recorder{File: recordFile, Redact: redact}
+		if verbose > 0 {
+			fmt.Println("record HTTP requests to", recordFile)
+		}
+	}
+	if replayFile != "" {
+		replayer, err := oreClient.NewReplayer(replayFile, redact)
+		if err != nil {
+			return err
+		}
+		_replayer = replayer
+		fmt.Fprintln(os.Stderr, "WARNING: replay HTTP responses from", replayFile, "instead of OreCast services")
+	}
+	return nil
+}
+
+// helper function to check if client replays HTTP responses from cassette
+func replaying() bool {
+	return _replayer != nil
+}
+
+// helper function to wrap HTTP transport into recorder or replayer of cassette
+func cassetteTransport(rt http.RoundTripper) http.RoundTripper {
+	if _replayer != nil {
+		return _replayer
+	}
+	if _recorder != nil {
+		// every client records via its own transport into shared cassette
+		return _recorder.WithBase(rt)
+	}
+	return rt
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	oreClient "github.com/OreCast/client/client"
+)
+
+// TestRecordConcurrentClients tests that HTTP clients created concurrently in
+// record mode, e.g. by doctor probes, keep their own transports
+func TestRecordConcurrentClients(t *testing.T) {
+	saved := _recorder
+	defer func() { _recorder = saved }()
+	_recorder = &oreClient.Recorder{File: filepath.Join(t.TempDir(), "cassette.json"), Redact: redact}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	clients := make([]*http.Client, 4)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := newHTTPClient(doctorTimeout, i)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			clients[i] = client
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+	for i, client := range clients {
+		rec, ok := client.Transport.(*oreClient.Recorder)
+		if !ok {
+			t.Fatalf("client %d does not record, transport %T", i, client.Transport)
+		}
+		rt, ok := rec.Base.(*oreClient.RetryTransport)
+		if !ok || rt.Retries != i {
+			t.Errorf("client %d records via transport of other client", i)
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package cmd
 
+import (
+	oreClient "github.com/OreCast/client/client"
+)
+
 // Response represences response from OreCast service
-type Response struct {
-	Status string `json:"status"`
-	Error  any    `json:"error,omitempty"`
-}
+type Response = oreClient.Response
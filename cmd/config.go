@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	oreConfig "github.com/OreCast/common/config"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ClientConfig represents client specific configuration which is not part of
+// common OreCast configuration, it is read from client section of config file
+type ClientConfig struct {
+	TokenSecret    string        `mapstructure:"token_secret"`         // HS256 shared secret to verify tokens
+	TokenPublicKey string        `mapstructure:"token_public_key"`     // PEM public key file to verify RS256/ES256 tokens
+	JWKSURL        string        `mapstructure:"jwks_url"`             // JWKS endpoint of Authz service
+	RefreshWindow  time.Duration `mapstructure:"refresh_window"`       // refresh tokens expiring within this window
+	APIKey         string        `mapstructure:"api_key"`              // API key used instead of user credentials
+	Bootstrap      bool          `mapstructure:"bootstrap"`            // bootstrap service URLs from Discovery service
+	DiscoveryTTL   time.Duration `mapstructure:"discovery_ttl"`        // how long discovered service URLs are cached
+	CABundle       string        `mapstructure:"ca_bundle"`            // PEM file with additional CA certificates
+	ClientCert     string        `mapstructure:"client_cert"`          // PEM client certificate for mutual TLS
+	ClientKey      string        `mapstructure:"client_key"`           // PEM client key for mutual TLS
+	Proxy          string        `mapstructure:"proxy"`                // proxy URL of all HTTP requests
+	Insecure       bool          `mapstructure:"insecure_skip_verify"` // skip verification of server certificates
+	LegacyTokenKey bool          `mapstructure:"legacy_token_key"`     // verify HS256 tokens with public client id
+}
+
+// client configuration
+var _clientConfig ClientConfig
+
+// location of configuration file in use
+var _configFile string
+
+// helper function to return default location of configuration file
+func defaultConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".orecast.yaml"), nil
+}
+
+// helper function to parse OreCast configuration, unlike common ParseConfig
+// it does not fail when configuration file does not exist yet such that it
+// can be created by config init command. The context profile is applied over
+// user configuration file and without --config flag the project .orecast.yaml
+// file found in current directory or its parents is merged over both.
+func parseConfig(cfile string) (oreConfig.OreCastConfig, error) {
+	var config oreConfig.OreCastConfig
+	explicit := cfile != ""
+	if !explicit {
+		fname, err := defaultConfigFile()
+		if err != nil {
+			return config, err
+		}
+		cfile = fname
+	}
+	_configFile = cfile
+	viper.SetConfigFile(cfile)
+	viper.AutomaticEnv()
+	if err := viper.ReadInConfig(); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return config, fmt.Errorf("unable to parse %s, error %v", cfile, err)
+		}
+		if verbose > 0 {
+			fmt.Printf("WARNING: config file %s does not exist, please run orecast config init\n", cfile)
+		}
+	}
+	if !explicit {
+		// project configuration is only used when config file is not explicitly provided
+		if fname := findProjectConfig(cfile); fname != "" {
+			if err := readProjectConfig(fname); err != nil {
+				return config, err
+			}
+		}
+	}
+	if err := applyContext(); err != nil {
+		return config, err
+	}
+	if err := mergeProjectConfig(); err != nil {
+		return config, err
+	}
+	if err := bindOverrides(); err != nil {
+		return config, err
+	}
+	if err := viper.Unmarshal(&config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// helper function to decode empty string into zero duration, unset override
+// flags have empty default value
+func emptyDurationHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() == reflect.String && to == reflect.TypeOf(time.Duration(0)) && data == "" {
+		return time.Duration(0), nil
+	}
+	return data, nil
+}
+
+// helper function to read client configuration, it should be called
+// after OreCast configuration is parsed. We unmarshal all settings instead of
+// client key since the latter ignores flags and environment variables bound to
+// client sub-keys when config file does not have client section.
+func parseClientConfig() error {
+	var config struct {
+		Client ClientConfig `mapstructure:"client"`
+	}
+	if err := viper.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		emptyDurationHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+	))); err != nil {
+		return err
+	}
+	_clientConfig = config.Client
+	return parseProjectDefaults()
+}
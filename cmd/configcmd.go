@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// default service URLs suggested by config init wizard
+var defaultServiceURLs = []struct {
+	Key   string
+	Label string
+	URL   string
+}{
+	{"services.frontend_url", "Frontend URL", "http://localhost:9000"},
+	{"services.discovery_url", "Discovery URL", "http://localhost:8320"},
+	{"services.metadata_url", "MetaData URL", "http://localhost:8300"},
+	{"services.datamanagement_url", "DataManagement URL", "http://localhost:8340"},
+	{"services.databookkeeping_url", "DataBookkeeping URL", "http://localhost:8310"},
+	{"services.authz_url", "Authz URL", "http://localhost:8380"},
+}
+
+// secretKeys lists configuration keys which hold sensitive values
+var secretKeys = []string{"client_secret", "token_secret", "api_key", "secret", "password", "access_secret"}
+
+// helper function to check if configuration key holds sensitive value
+func secretKey(key string) bool {
+	parts := strings.Split(strings.ToLower(key), ".")
+	leaf := parts[len(parts)-1]
+	for _, s := range secretKeys {
+		if leaf == s {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to redact sensitive values in configuration map
+func redactConfig(settings map[string]any) map[string]any {
+	out := make(map[string]any)
+	for key, val := range settings {
+		if m, ok := val.(map[string]any); ok {
+			out[key] = redactConfig(m)
+		} else if secretKey(key) && val != "" {
+			out[key] = redactedValue
+		} else {
+			out[key] = val
+		}
+	}
+	return out
+}
+
+// helper function to read configuration file into separate viper instance,
+// it allows to modify the file without merged context or override values
+func readConfigFile(fname string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(fname)
+	if err := v.ReadInConfig(); err != nil {
+		if _, serr := os.Stat(fname); errors.Is(serr, os.ErrNotExist) {
+			v.SetConfigType("yaml")
+			return v, nil
+		}
+		return v, err
+	}
+	return v, nil
+}
+
+// helper function to write configuration file readable only by its owner
+func writeConfigFile(v *viper.Viper, fname string) error {
+	if err := v.WriteConfigAs(fname); err != nil {
+		return err
+	}
+	return os.Chmod(fname, 0600)
+}
+
+// helper function to detect indentation of YAML file, it defaults to 2 spaces
+func yamlIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" && trimmed != line && !strings.HasPrefix(trimmed, "#") {
+			return len(line) - len(trimmed)
+		}
+	}
+	return 2
+}
+
+// helper function to set value of dotted key in YAML configuration file, the
+// file is edited as YAML node tree to preserve comments and order of keys
+func setYAMLKey(fname, key string, val any) error {
+	data, err := os.ReadFile(fname)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	node := doc.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s does not contain YAML mapping", fname)
+	}
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, part) {
+				child = node.Content[j+1]
+				break
+			}
+		}
+		if i == len(parts)-1 {
+			var value yaml.Node
+			if err := value.Encode(val); err != nil {
+				return err
+			}
+			if child == nil {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, &value)
+			} else {
+				value.HeadComment, value.LineComment, value.FootComment = child.HeadComment, child.LineComment, child.FootComment
+				*child = value
+			}
+			break
+		}
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		} else if child.Kind == yaml.ScalarNode && child.Tag == "!!null" {
+			// empty section, e.g. "client:" without keys
+			child.Kind, child.Tag, child.Value = yaml.MappingNode, "", ""
+		} else if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("key %s is not a section", strings.Join(parts[:i+1], "."))
+		}
+		node = child
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(yamlIndent(data))
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fname, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Chmod(fname, 0600)
+}
+
+// helper function to set value of dotted key in configuration file, YAML files
+// keep their comments while other formats are rewritten without them
+func setConfigKey(fname, key string, val any) error {
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".yaml", ".yml", "":
+		return setYAMLKey(fname, key, val)
+	}
+	v, err := readConfigFile(fname)
+	if err != nil {
+		return err
+	}
+	v.Set(key, val)
+	return writeConfigFile(v, fname)
+}
+
+// helper function to convert string value into YAML scalar, e.g. bool or int
+func configValue(value string) any {
+	var val any
+	if err := yaml.Unmarshal([]byte(value), &val); err != nil || val == nil {
+		return value
+	}
+	switch val.(type) {
+	case map[string]any, []any:
+		return value
+	}
+	return val
+}
+
+// helper function to get user input with default value used on empty input
+func defaultPrompt(label, value string) string {
+	fmt.Fprintf(os.Stderr, "%s [%s]: ", label, value)
+	line, _ := stdinReader.ReadString('\n')
+	if s := strings.TrimSpace(line); s != "" {
+		return s
+	}
+	return value
+}
+
+// helper function to interactively create new configuration file
+func configInit(force bool) {
+	if _, err := os.Stat(_configFile); err == nil && !force {
+		printError(fmt.Sprintf("config file %s already exists, use --force to overwrite it", _configFile))
+		os.Exit(1)
+	}
+	fmt.Printf("Create OreCast configuration %s\n", _configFile)
+	fmt.Println("press Enter to accept default value shown in brackets")
+	v := viper.New()
+	v.SetConfigType("yaml")
+	for _, s := range defaultServiceURLs {
+		val := defaultPrompt(s.Label, s.URL)
+		v.Set(s.Key, strings.TrimRight(val, "/"))
+	}
+	clientId := inputPrompt("Authz client id:")
+	clientSecret := passwordPrompt("Authz client secret:")
+	if clientId == "" || clientSecret == "" {
+		printError("Authz client id and client secret are required")
+		os.Exit(1)
+	}
+	addSecret(clientSecret)
+	v.Set("authz.client_id", clientId)
+	v.Set("authz.client_secret", clientSecret)
+	if err := writeConfigFile(v, _configFile); err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: configuration was written to %s\n", _configFile)
+}
+
+// helper function to print configuration with secrets redacted
+func configView() {
+	v, err := readConfigFile(_configFile)
+	if err != nil {
+		exitError(err)
+	}
+	data, err := yaml.Marshal(redactConfig(v.AllSettings()))
+	if err != nil {
+		exitError(err)
+	}
+	fmt.Printf("# %s\n", _configFile)
+	fmt.Print(string(data))
+}
+
+// helper function to print effective configuration resolved from flags,
+// environment, configuration files and defaults with secrets redacted
+func configEffective() {
+	settings := viper.AllSettings()
+	delete(settings, "contexts")
+	delete(settings, "current_context")
+	data, err := yaml.Marshal(redactConfig(settings))
+	if err != nil {
+		exitError(err)
+	}
+	fmt.Printf("# effective configuration, config file %s", _configFile)
+	if name := currentContext(); name != "" {
+		fmt.Printf(", context %s", name)
+	}
+	fmt.Println()
+	fmt.Print(string(data))
+	fmt.Println("\n# sources, precedence: flag > env > discovery > project file > context > user file > defaults")
+	for _, o := range configOverrides {
+		fmt.Printf("# %-28s %s\n", o.Key, overrideSource(o))
+	}
+}
+
+// helper function to format value of configuration key with secrets
+// redacted the same way as in config view
+func configGetValue(key string) (string, error) {
+	if !viper.IsSet(key) {
+		return "", fmt.Errorf("key %s is not set", key)
+	}
+	val := viper.Get(key)
+	if m, ok := val.(map[string]any); ok {
+		data, err := yaml.Marshal(redactConfig(m))
+		return string(data), err
+	}
+	if secretKey(key) && val != "" {
+		return redactedValue + "\n", nil
+	}
+	return redact(fmt.Sprint(val)) + "\n", nil
+}
+
+// helper function to print value of configuration key
+func configGet(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(configUsage)
+	}
+	out, err := configGetValue(strings.ToLower(args[1]))
+	if err != nil {
+		exitError(err)
+	}
+	fmt.Print(out)
+}
+
+// helper function to set value of configuration key in configuration file
+func configSet(args []string) {
+	if len(args) != 3 {
+		exitWithUsage(configUsage)
+	}
+	key, value := strings.ToLower(args[1]), args[2]
+	if err := setConfigKey(_configFile, key, configValue(value)); err != nil {
+		exitError(err)
+	}
+	if secretKey(key) {
+		value = redactedValue
+	}
+	fmt.Printf("SUCCESS: %s=%s\n", key, value)
+}
+
+// helper function to provide usage of config option
+func configUsage() {
+	fmt.Println("orecast config <init|view|get|set> [value]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# create new configuration file:")
+	fmt.Println("orecast config init")
+	fmt.Println("\n# show configuration with secrets redacted:")
+	fmt.Println("orecast config view")
+	fmt.Println("\n# show effective configuration including environment and flag overrides:")
+	fmt.Println("orecast config view --effective")
+	fmt.Println("\n# get value of configuration key, secrets are redacted:")
+	fmt.Println("orecast config get services.authz_url")
+	fmt.Println("\n# set value of configuration key, comments are only preserved in YAML files:")
+	fmt.Println("orecast config set services.authz_url https://orecast.com/authz")
+}
+
+func configCommand() *cobra.Command {
+	var force, effective bool
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "OreCast config command",
+		Long: `OreCast config command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				configUsage()
+			} else if args[0] == "init" {
+				configInit(force)
+			} else if args[0] == "view" && effective {
+				configEffective()
+			} else if args[0] == "view" {
+				configView()
+			} else if args[0] == "get" {
+				configGet(args)
+			} else if args[0] == "set" {
+				configSet(args)
+			} else {
+				unsupportedOptions(args, configUsage)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing configuration file")
+	cmd.Flags().BoolVar(&effective, "effective", false, "show effective configuration resolved from flags, environment and files")
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		configUsage()
+		return nil
+	})
+	return cmd
+}
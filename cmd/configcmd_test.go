@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestConfigGetValue tests that config get redacts secrets like config view
+func TestConfigGetValue(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("client.token_secret", "tsecret")
+	viper.Set("client.api_key", "")
+	viper.Set("client.refresh_window", "5m")
+	viper.Set("authz.client_id", "cid")
+	viper.Set("authz.client_secret", "csecret")
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"client.token_secret", "***\n", false},
+		{"authz.client_secret", "***\n", false},
+		{"client.api_key", "\n", false},
+		{"client.refresh_window", "5m\n", false},
+		{"authz", "client_id: cid\nclient_secret: '***'\n", false},
+		{"client.missing", "", true},
+	}
+	for _, tt := range tests {
+		got, err := configGetValue(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.key, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Used for context flag.
+var contextName string
+
+// contextKeys lists configuration keys which are only taken from context
+// profile and never inherited from top level configuration, such that e.g.
+// client secret of one deployment is never sent to Authz service of another
+var contextKeys = []string{
+	"services",
+	"authz",
+	"client.token_secret",
+	"client.token_public_key",
+	"client.jwks_url",
+	"client.api_key",
+	"client.client_cert",
+	"client.client_key",
+}
+
+// helper function to return name of the context in use, the --context flag
+// takes precedence over ORECAST_CONTEXT environment and current_context
+// key of project and user configuration files
+func currentContext() string {
+	if contextName != "" {
+		return contextName
+	}
+	if name := os.Getenv("ORECAST_CONTEXT"); name != "" {
+		return name
+	}
+	if _projectConfig != nil && _projectConfig.IsSet("current_context") {
+		return _projectConfig.GetString("current_context")
+	}
+	return viper.GetString("current_context")
+}
+
+// helper function to check if configuration key is only taken from context profile
+func contextKey(key string) bool {
+	for _, k := range contextKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to set value of dotted key in nested configuration map
+func setNested(m map[string]any, key string, val any) {
+	parts := strings.Split(key, ".")
+	for _, p := range parts[:len(parts)-1] {
+		sub, ok := m[p].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[p] = sub
+		}
+		m = sub
+	}
+	m[parts[len(parts)-1]] = val
+}
+
+// helper function to return names of all contexts defined in configuration
+func contextNames() []string {
+	var names []string
+	for name := range viper.GetStringMap("contexts") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// helper function to apply named context on top of user configuration, the
+// context profile overrides top level client section while Services, Authz
+// and credentials of client section are only taken from the profile
+func applyContext() error {
+	name := currentContext()
+	if name == "" {
+		return nil
+	}
+	profile := viper.Sub("contexts." + name)
+	if profile == nil {
+		return fmt.Errorf("context '%s' is not defined in %s", name, viper.ConfigFileUsed())
+	}
+	settings := profile.AllSettings()
+	for _, key := range viper.AllKeys() {
+		if contextKey(key) && !profile.IsSet(key) {
+			// reset top level value which is not defined by the profile
+			setNested(settings, key, "")
+		}
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// helper function to update current context in configuration file
+func useContext(name string) error {
+	found := false
+	for _, n := range contextNames() {
+		if n == name {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("context '%s' is not defined in %s", name, viper.ConfigFileUsed())
+	}
+	fname := viper.ConfigFileUsed()
+	if fname == "" {
+		return errors.New("no configuration file in use")
+	}
+	// edit the file itself to not write merged context values back to it
+	return setConfigKey(fname, "current_context", name)
+}
+
+// helper function to provide usage of context option
+func contextUsage() {
+	fmt.Println("orecast context <ls|use|current> [value]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# list all contexts:")
+	fmt.Println("orecast context ls")
+	fmt.Println("\n# switch to staging context:")
+	fmt.Println("orecast context use staging")
+	fmt.Println("\n# show current context:")
+	fmt.Println("orecast context current")
+	fmt.Println("\n# run single command against production context:")
+	fmt.Println("orecast --context production site ls")
+}
+
+// helper function to list contexts
+func contextList() {
+	current := currentContext()
+	for _, name := range contextNames() {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		authzURL := viper.GetString("contexts." + name + ".services.authz_url")
+		fmt.Printf("%s %-20s %s\n", marker, name, authzURL)
+	}
+}
+
+func contextCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "OreCast context command",
+		Long: `OreCast context command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				contextUsage()
+			} else if args[0] == "ls" {
+				contextList()
+			} else if args[0] == "current" {
+				if name := currentContext(); name != "" {
+					fmt.Println(name)
+				} else {
+					fmt.Println("default")
+				}
+			} else if args[0] == "use" {
+				if len(args) != 2 {
+					exitWithUsage(contextUsage)
+				}
+				if err := useContext(args[1]); err != nil {
+					exitError(err)
+				}
+				fmt.Printf("SUCCESS: switched to context %s\n", args[1])
+			} else {
+				unsupportedOptions(args, contextUsage)
+			}
+		},
+	}
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		contextUsage()
+		return nil
+	})
+	return cmd
+}
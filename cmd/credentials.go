@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Used for credentials flags.
+var (
+	passwordStdin bool
+	passwordFile  string
+)
+
+// helper function to read password from given file
+func readPasswordFile(fname string) (string, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return "", err
+	}
+	pass := strings.TrimRight(string(data), "\r\n")
+	if pass == "" {
+		return "", fmt.Errorf("empty password in %s", fname)
+	}
+	return pass, nil
+}
+
+// helper function to obtain user password from non-interactive sources,
+// the lookup order is --password-stdin, --password-file, ORECAST_PASSWORD
+// and ORECAST_PASSWORD_FILE environment variables
+func nonInteractivePassword() (string, error) {
+	if passwordStdin {
+		line, err := stdinReader.ReadString('\n')
+		pass := strings.TrimRight(line, "\r\n")
+		if pass == "" {
+			if err != nil {
+				return "", fmt.Errorf("unable to read password from stdin: %w", err)
+			}
+			return "", errors.New("empty password provided via stdin")
+		}
+		return pass, nil
+	}
+	if passwordFile != "" {
+		return readPasswordFile(passwordFile)
+	}
+	if pass := os.Getenv("ORECAST_PASSWORD"); pass != "" {
+		return pass, nil
+	}
+	if fname := os.Getenv("ORECAST_PASSWORD_FILE"); fname != "" {
+		return readPasswordFile(fname)
+	}
+	return "", nil
+}
+
+// helper function to obtain user credentials, it uses ORECAST_USER and
+// non-interactive password sources and only falls back to prompts when they
+// are not provided, the prompts return empty input on closed stdin instead of
+// blocking
+func credentials() (string, string, error) {
+	user := os.Getenv("ORECAST_USER")
+	if user == "" {
+		if passwordStdin {
+			return "", "", errors.New("--password-stdin requires ORECAST_USER environment variable")
+		}
+		user = inputPrompt("OreCast username:")
+	}
+	if user == "" {
+		return "", "", errors.New("no OreCast username provided, please use ORECAST_USER environment variable")
+	}
+	pass, err := nonInteractivePassword()
+	if err != nil {
+		return user, pass, err
+	}
+	if pass == "" {
+		pass = passwordPrompt("OreCast password:")
+	}
+	if pass == "" {
+		return user, pass, errors.New("no OreCast password provided, please use --password-stdin, --password-file or ORECAST_PASSWORD")
+	}
+	return user, pass, nil
+}
@@ -1,36 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
+	oreClient "github.com/OreCast/client/client"
 	"github.com/spf13/cobra"
 )
 
-type DBSRecord map[string]any
-
-// helper function to fetch data from DBS service
-func getData(rurl string) []DBSRecord {
-	var results []DBSRecord
-	if verbose > 0 {
-		fmt.Println("HTTP GET", rurl)
-	}
-	resp, err := http.Get(rurl)
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		return results
-	}
-	defer resp.Body.Close()
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&results); err != nil {
-		fmt.Println("ERROR:", err)
-		return results
-	}
-	return results
-}
+// DBSRecord represents record returned by DataBookkeeping service
+type DBSRecord = oreClient.DBSRecord
 
 // helper function to print dbs record items
 func printResults(rec DBSRecord) {
@@ -51,11 +30,18 @@ func printResults(rec DBSRecord) {
 func dbsListRecord(args []string) {
 	if len(args) == 1 {
 		fmt.Println("WARNING: please provide dbs attribute")
-		os.Exit(1)
+		exitWithUsage(dbsUsage)
 	}
 	if args[1] == "datasets" {
-		rurl := fmt.Sprintf("%s/datasets", _oreConfig.Services.DataBookkeepingURL)
-		for _, rec := range getData(rurl) {
+		prefix := _defaults.DatasetPrefix
+		if len(args) > 2 {
+			prefix = args[2]
+		}
+		records, err := orecastClient().ListDatasets(cmdContext(), prefix)
+		if err != nil {
+			exitError(err)
+		}
+		for _, rec := range records {
 			printResults(rec)
 		}
 	} else {
@@ -77,6 +63,8 @@ func dbsUsage() {
 	fmt.Println("Examples:")
 	fmt.Println("\n# list all dbs records:")
 	fmt.Println("orecast dbs ls <dataset|site|file>")
+	fmt.Println("\n# list datasets with given prefix (default is project dataset_prefix):")
+	fmt.Println("orecast dbs ls datasets /Cornell/")
 	fmt.Println("\n# remove dbs-data record:")
 	fmt.Println("orecast dbs rm <dataset|site|file>")
 	fmt.Println("\n# add dbs-data record:")
@@ -99,7 +87,7 @@ func dbsCommand() *cobra.Command {
 			} else if args[0] == "rm" {
 				dbsDeleteRecord(args)
 			} else {
-				fmt.Printf("WARNING: unsupported option(s) %+v", args)
+				unsupportedOptions(args, dbsUsage)
 			}
 		},
 	}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// deviceGrantType represents OAuth2 device code grant type, see RFC 8628
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorization represents response of Authz device authorization endpoint
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// helper function to start device authorization grant in Authz service
+func deviceAuthorize(scope string) (DeviceAuthorization, error) {
+	var auth DeviceAuthorization
+	rurl := fmt.Sprintf("%s/oauth/device/authorize", _oreConfig.Services.AuthzURL)
+	if verbose > 0 {
+		debugPrintln("HTTP POST", rurl)
+	}
+	form := url.Values{}
+	form.Set("client_id", _oreConfig.Authz.ClientId)
+	form.Set("scope", scope)
+	resp, err := httpPost(rurl, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return auth, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return auth, err
+	}
+	if verbose > 1 {
+		debugPrintln("## device authorization response", string(data))
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oerr OAuthError
+		if err := json.Unmarshal(data, &oerr); err == nil && oerr.Code != "" {
+			return auth, &oerr
+		}
+		return auth, fmt.Errorf("device authorization failed with status %s", resp.Status)
+	}
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return auth, err
+	}
+	if auth.DeviceCode == "" || auth.UserCode == "" {
+		return auth, errors.New("Authz service returned incomplete device authorization")
+	}
+	return auth, nil
+}
+
+// helper function to poll Authz token endpoint until user approves device
+// authorization, it follows polling interval and slow_down responses
+func devicePoll(auth DeviceAuthorization) (TokenResponse, error) {
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	form := url.Values{}
+	form.Set("grant_type", deviceGrantType)
+	form.Set("device_code", auth.DeviceCode)
+	form.Set("client_id", _oreConfig.Authz.ClientId)
+	for {
+		if auth.ExpiresIn > 0 && time.Now().After(deadline) {
+			return TokenResponse{}, errors.New("device code expired, please login again")
+		}
+		select {
+		case <-cmdContext().Done():
+			return TokenResponse{}, cmdContext().Err()
+		case <-time.After(interval):
+		}
+		token, err := tokenRequest(form)
+		if err == nil {
+			return token, nil
+		}
+		var oerr *OAuthError
+		if !errors.As(err, &oerr) {
+			return token, err
+		}
+		switch oerr.Code {
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			interval += 5 * time.Second
+			if verbose > 0 {
+				fmt.Println("Authz service requested slow down, poll interval", interval)
+			}
+		case "access_denied":
+			return token, errors.New("device authorization was denied")
+		case "expired_token":
+			return token, errors.New("device code expired, please login again")
+		default:
+			return token, err
+		}
+	}
+}
+
+// helper function to login using OAuth2 device authorization flow
+func deviceSession(scope string) (Session, error) {
+	auth, err := deviceAuthorize(scope)
+	if err != nil {
+		return Session{}, err
+	}
+	if auth.VerificationURIComplete != "" {
+		fmt.Printf("To authorize this device please visit:\n\n    %s\n\n", auth.VerificationURIComplete)
+	} else {
+		fmt.Printf("To authorize this device please visit:\n\n    %s\n\n", auth.VerificationURI)
+	}
+	fmt.Printf("and enter the code: %s\n", auth.UserCode)
+	fmt.Println("Waiting for authorization...")
+	token, err := devicePoll(auth)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := validateToken(token.AccessToken); err != nil {
+		return Session{}, err
+	}
+	session := Session{
+		Login:        tokenLogin(token.AccessToken),
+		Scope:        scope,
+		Grant:        deviceGrant,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+	return storeSession(session), nil
+}
@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorTimeout defines timeout of every network probe of doctor command
+const doctorTimeout = 5 * time.Second
+
+// maxClockSkew defines allowed difference between local and server clocks
+const maxClockSkew = 30 * time.Second
+
+// check statuses reported by doctor command
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+	checkSkip = "SKIP"
+)
+
+// Check represents result of single doctor check
+type Check struct {
+	Name   string // name of the check
+	Status string // PASS, WARN, FAIL or SKIP
+	Detail string // details of the check
+	Hint   string // remediation hint of failed check
+}
+
+// Probe represents result of probing single OreCast service
+type Probe struct {
+	Service    string        // name of the service
+	URL        string        // service URL
+	Checks     []Check       // DNS, TCP, TLS and HTTP checks
+	Latency    time.Duration // latency of HTTP request
+	ServerTime time.Time     // time reported by Date header of HTTP response
+}
+
+// helper function to list configured service URLs
+func serviceURLs() [][2]string {
+	s := _oreConfig.Services
+	return [][2]string{
+		{"frontend", s.FrontendURL},
+		{"discovery", s.DiscoveryURL},
+		{"metadata", s.MetaDataURL},
+		{"datamanagement", s.DataManagementURL},
+		{"databookkeeping", s.DataBookkeepingURL},
+		{"authz", s.AuthzURL},
+	}
+}
+
+// helper function to validate configuration
+func checkConfig() []Check {
+	var checks []Check
+	if _, err := os.Stat(_configFile); err != nil {
+		checks = append(checks, Check{
+			Name:   "config file",
+			Status: checkWarn,
+			Detail: fmt.Sprintf("%s does not exist", _configFile),
+			Hint:   "run orecast config init or use environment variables and flags",
+		})
+	} else {
+		checks = append(checks, Check{Name: "config file", Status: checkPass, Detail: _configFile})
+	}
+	for _, s := range serviceURLs() {
+		name := fmt.Sprintf("config %s_url", s[0])
+		if s[1] == "" {
+			// Authz service is required to obtain tokens
+			status := checkWarn
+			if s[0] == "authz" {
+				status = checkFail
+			}
+			checks = append(checks, Check{
+				Name:   name,
+				Status: status,
+				Detail: "not set",
+				Hint:   fmt.Sprintf("run orecast config set services.%s_url <url>", s[0]),
+			})
+			continue
+		}
+		u, err := url.Parse(s[1])
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			checks = append(checks, Check{
+				Name:   name,
+				Status: checkFail,
+				Detail: fmt.Sprintf("invalid URL %s", s[1]),
+				Hint:   "use http(s)://host:port form",
+			})
+			continue
+		}
+		checks = append(checks, Check{Name: name, Status: checkPass, Detail: s[1]})
+	}
+	if _oreConfig.Authz.ClientId == "" || _oreConfig.Authz.ClientSecret == "" {
+		checks = append(checks, Check{
+			Name:   "config authz client",
+			Status: checkFail,
+			Detail: "client_id or client_secret is not set",
+			Hint:   "set authz.client_id and authz.client_secret or ORECAST_CLIENT_ID and ORECAST_CLIENT_SECRET",
+		})
+	} else {
+		checks = append(checks, Check{Name: "config authz client", Status: checkPass, Detail: _oreConfig.Authz.ClientId})
+	}
+	return checks
+}
+
+// helper function to add failed check to the probe
+func (p Probe) fail(name string, err error, hint string) Probe {
+	p.Checks = append(p.Checks, Check{
+		Name:   name,
+		Status: checkFail,
+		Detail: redact(err.Error()),
+		Hint:   hint,
+	})
+	return p
+}
+
+// helper function to add check with warning to the probe
+func (p Probe) warn(name, detail, hint string) Probe {
+	p.Checks = append(p.Checks, Check{Name: name, Status: checkWarn, Detail: detail, Hint: hint})
+	return p
+}
+
+// helper function to add passed check to the probe
+func (p Probe) pass(name, detail string) Probe {
+	p.Checks = append(p.Checks, Check{Name: name, Status: checkPass, Detail: detail})
+	return p
+}
+
+// helper function to probe DNS, TCP, TLS and HTTP status of given service
+func probeService(service, rurl string) Probe {
+	probe := Probe{Service: service, URL: rurl}
+	u, err := url.Parse(rurl)
+	if err != nil || u.Host == "" {
+		return probe.fail(service+" url", fmt.Errorf("invalid URL %s", rurl), "fix service URL in configuration")
+	}
+	transport, err := newTransport(doctorTimeout)
+	if err != nil {
+		return probe.fail(service+" tls", err, "fix ca_bundle, client_cert, client_key or proxy in configuration")
+	}
+	if replaying() {
+		// responses come from cassette, network is not used
+		probe.Checks = append(probe.Checks, Check{
+			Name:   service + " network",
+			Status: checkSkip,
+			Detail: "DNS, TCP and TLS checks skipped, responses are replayed from cassette",
+		})
+		return probeHTTP(probe, u.String())
+	}
+	if proxy, err := transport.Proxy(&http.Request{URL: u}); err == nil && proxy != nil {
+		// direct DNS, TCP and TLS checks are meaningless when requests go via proxy
+		probe.Checks = append(probe.Checks, Check{
+			Name:   service + " proxy",
+			Status: checkSkip,
+			Detail: fmt.Sprintf("DNS, TCP and TLS checks skipped, requests use proxy %s", redact(proxy.Redacted())),
+		})
+		return probeHTTP(probe, u.String())
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	// DNS
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(cmdContext(), doctorTimeout)
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	cancel()
+	if err != nil {
+		return probe.fail(service+" dns", err, fmt.Sprintf("check that %s is a valid host name and DNS is reachable", host))
+	}
+	probe = probe.pass(service+" dns", fmt.Sprintf("%s -> %s (%s)", host, strings.Join(addrs, ","), time.Since(start).Round(time.Millisecond)))
+
+	// TCP
+	addr := net.JoinHostPort(host, port)
+	start = time.Now()
+	conn, err := net.DialTimeout("tcp", addr, doctorTimeout)
+	if err != nil {
+		return probe.fail(service+" tcp", err, fmt.Sprintf("check that %s service is running and %s is not blocked by firewall", service, addr))
+	}
+	conn.Close()
+	probe = probe.pass(service+" tcp", fmt.Sprintf("%s (%s)", addr, time.Since(start).Round(time.Millisecond)))
+
+	// TLS
+	if u.Scheme == "https" {
+		start = time.Now()
+		dialer := &net.Dialer{Timeout: doctorTimeout}
+		tlsConfig := transport.TLSClientConfig.Clone()
+		tlsConfig.ServerName = host
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+		if err != nil {
+			return probe.fail(service+" tls", err, "check server certificate and configure ca_bundle or client_cert/client_key")
+		}
+		state := conn.ConnectionState()
+		detail := fmt.Sprintf("%s (%s)", tls.VersionName(state.Version), time.Since(start).Round(time.Millisecond))
+		if len(state.PeerCertificates) > 0 {
+			cert := state.PeerCertificates[0]
+			detail = fmt.Sprintf("%s, certificate expires %s", detail, cert.NotAfter.Format(time.RFC3339))
+		}
+		conn.Close()
+		probe = probe.pass(service+" tls", detail)
+	}
+
+	return probeHTTP(probe, rurl)
+}
+
+// helper function to probe HTTP status and latency of given service
+func probeHTTP(probe Probe, rurl string) Probe {
+	service := probe.Service
+	// probe service once without retries to report its actual state
+	client, err := newHTTPClient(doctorTimeout, 0)
+	if err != nil {
+		return probe.fail(service+" tls", err, "fix ca_bundle, client_cert, client_key or proxy in configuration")
+	}
+	client.Timeout = doctorTimeout
+	start := time.Now()
+	req, err := http.NewRequestWithContext(cmdContext(), http.MethodGet, rurl, nil)
+	if err != nil {
+		return probe.fail(service+" http", err, "fix service URL in configuration")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return probe.fail(service+" http", err, fmt.Sprintf("check that %s is served by %s service", rurl, service))
+	}
+	resp.Body.Close()
+	probe.Latency = time.Since(start)
+	if t, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		probe.ServerTime = t.Add(probe.Latency / 2)
+	}
+	detail := fmt.Sprintf("status %s, latency %s", resp.Status, probe.Latency.Round(time.Millisecond))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return probe.fail(service+" http", fmt.Errorf("status %s", resp.Status), fmt.Sprintf("check logs of %s service", service))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return probe.warn(service+" http", detail, fmt.Sprintf("check that %s is base URL of %s service", rurl, service))
+	}
+	return probe.pass(service+" http", detail)
+}
+
+// helper function to concurrently probe all configured services
+func probeServices() []Probe {
+	var probes []Probe
+	var wg sync.WaitGroup
+	for _, s := range serviceURLs() {
+		if s[1] == "" {
+			continue
+		}
+		probes = append(probes, Probe{Service: s[0], URL: s[1]})
+	}
+	for i := range probes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			probes[i] = probeService(probes[i].Service, probes[i].URL)
+		}(i)
+	}
+	wg.Wait()
+	return probes
+}
+
+// helper function to check token fetch from Authz service, the token is
+// always requested from token endpoint instead of token cache such that
+// client credentials or API key are actually verified
+func checkToken() (Check, string) {
+	if _oreConfig.Services.AuthzURL == "" {
+		return Check{Name: "token", Status: checkSkip, Detail: "Authz URL is not set"}, ""
+	}
+	var token string
+	var err error
+	detail := "obtained token from token endpoint with client credentials"
+	if apiKey() != "" {
+		var session Session
+		session, err = apiKeySession(scopeRead)
+		token = session.AccessToken
+		detail = "obtained token from token endpoint with API key"
+	} else {
+		form := url.Values{}
+		form.Set("grant_type", "client_credentials")
+		form.Set("scope", scopeRead)
+		var aToken TokenResponse
+		aToken, err = tokenRequest(form)
+		if err == nil {
+			token = aToken.AccessToken
+			err = validateToken(token)
+		}
+	}
+	if err != nil {
+		hint := "check authz.client_id and authz.client_secret"
+		var oerr *OAuthError
+		if errors.Is(err, errTransportConfig) {
+			hint = "fix ca_bundle, client_cert, client_key or proxy in configuration"
+		} else if errors.As(err, &oerr) && oerr.Code == "invalid_grant" {
+			hint = "check client.api_key or create new one with orecast apikey create"
+		} else if errors.Is(err, errNoTokenSecret) || errors.Is(err, errInvalidSignature) {
+			hint = "check client.token_secret, client.token_public_key or client.jwks_url"
+		}
+		return Check{Name: "token", Status: checkFail, Detail: redact(err.Error()), Hint: hint}, ""
+	}
+	if login := tokenLogin(token); login != "" {
+		detail = fmt.Sprintf("%s of %s", detail, login)
+	}
+	return Check{Name: "token", Status: checkPass, Detail: detail}, token
+}
+
+// helper function to check clock skew against Authz server time and token timestamps
+func checkClockSkew(probes []Probe, token string) []Check {
+	var checks []Check
+	now := time.Now()
+	for _, p := range probes {
+		if p.Service != "authz" || p.ServerTime.IsZero() {
+			continue
+		}
+		skew := now.Sub(p.ServerTime).Round(time.Second)
+		check := Check{Name: "clock skew server", Status: checkPass, Detail: fmt.Sprintf("%s relative to Authz server", skew)}
+		if skew > maxClockSkew || skew < -maxClockSkew {
+			check.Status = checkFail
+			check.Hint = "synchronize your system clock, e.g. enable NTP"
+		}
+		checks = append(checks, check)
+	}
+	if token == "" {
+		return checks
+	}
+	info, err := decodeToken(token, false)
+	if err != nil {
+		return append(checks, Check{Name: "clock skew token", Status: checkWarn, Detail: redact(err.Error())})
+	}
+	check := Check{Name: "clock skew token", Status: checkPass}
+	switch {
+	case info.IssuedAt != nil && info.IssuedAt.Sub(now) > maxClockSkew:
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("token issued %s in the future", info.IssuedAt.Sub(now).Round(time.Second))
+		check.Hint = "local clock is behind, synchronize your system clock"
+	case info.ExpiresAt != nil && info.ExpiresAt.Before(now):
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("token expired %s ago", now.Sub(*info.ExpiresAt).Round(time.Second))
+		check.Hint = "local clock is ahead or token is stale, synchronize your system clock or run orecast logout"
+	case info.IssuedAt != nil:
+		check.Detail = fmt.Sprintf("token issued at %s", formatTime(info.IssuedAt))
+	default:
+		check.Status = checkSkip
+		check.Detail = "token has no iat claim"
+	}
+	return append(checks, check)
+}
+
+// helper function to print doctor check
+func printCheck(c Check) {
+	fmt.Printf("%-5s %-28s %s\n", c.Status, c.Name, c.Detail)
+	if c.Hint != "" && (c.Status == checkFail || c.Status == checkWarn) {
+		fmt.Printf("      %-28s hint: %s\n", "", c.Hint)
+	}
+}
+
+// helper function to run all doctor checks, it returns number of failed checks
+func doctor(skipToken bool) int {
+	var checks []Check
+	checks = append(checks, checkConfig()...)
+	probes := probeServices()
+	for _, p := range probes {
+		checks = append(checks, p.Checks...)
+	}
+	var token string
+	if skipToken {
+		checks = append(checks, Check{Name: "token", Status: checkSkip, Detail: "skipped by --skip-token"})
+	} else if replaying() {
+		// replayed tokens are redacted and can't be verified
+		checks = append(checks, Check{Name: "token", Status: checkSkip, Detail: "skipped, responses are replayed from cassette"})
+	} else {
+		var check Check
+		check, token = checkToken()
+		checks = append(checks, check)
+	}
+	if replaying() {
+		// Date headers of replayed responses were recorded in the past
+		checks = append(checks, Check{Name: "clock skew", Status: checkSkip, Detail: "skipped, responses are replayed from cassette"})
+	} else {
+		checks = append(checks, checkClockSkew(probes, token)...)
+	}
+
+	failed := 0
+	for _, c := range checks {
+		printCheck(c)
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+	} else {
+		fmt.Println("\nall checks passed")
+	}
+	return failed
+}
+
+// helper function to provide usage of doctor option
+func doctorUsage() {
+	fmt.Println("orecast doctor [--skip-token]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# check configuration, services, token and clock skew:")
+	fmt.Println("orecast doctor")
+	fmt.Println("\n# check configuration and services without obtaining token:")
+	fmt.Println("orecast doctor --skip-token")
+}
+
+func doctorCommand() *cobra.Command {
+	var skipToken bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "OreCast health check command",
+		Long: `OreCast health check command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 {
+				unsupportedOptions(args, doctorUsage)
+			}
+			if doctor(skipToken) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&skipToken, "skip-token", false, "do not obtain token from Authz service")
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		doctorUsage()
+		return nil
+	})
+	return cmd
+}
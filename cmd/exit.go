@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+
+	oreClient "github.com/OreCast/client/client"
+)
+
+// process exit codes of failed commands
+const (
+	exitFailure     = 1   // general failure
+	exitUsage       = 2   // wrong command line usage
+	exitAuth        = 3   // authentication or authorization failure
+	exitNotFound    = 4   // requested record does not exist
+	exitConflict    = 5   // record already exists or was modified
+	exitServer      = 6   // OreCast service failure
+	exitNetwork     = 7   // OreCast service is unreachable or timed out
+	exitInterrupted = 130 // command was interrupted by Ctrl-C
+)
+
+// helper function to map error to process exit code
+func exitCode(err error) int {
+	var oerr *OAuthError
+	var uerr *url.Error
+	var operr *net.OpError
+	switch {
+	case interrupted():
+		return exitInterrupted
+	case errors.Is(err, oreClient.ErrUnmatchedRequest), errors.Is(err, errTransportConfig):
+		return exitFailure
+	case errors.Is(err, oreClient.ErrAuth), errors.As(err, &oerr),
+		errors.Is(err, errAccountLocked), errors.Is(err, errInvalidOTP):
+		return exitAuth
+	case errors.Is(err, oreClient.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, oreClient.ErrConflict):
+		return exitConflict
+	case errors.Is(err, oreClient.ErrServer):
+		return exitServer
+	case errors.Is(err, oreClient.ErrNetwork), errors.As(err, &uerr), errors.As(err, &operr):
+		return exitNetwork
+	}
+	return exitFailure
+}
+
+// helper function to provide hint for given exit code
+func exitHint(code int) string {
+	switch code {
+	case exitAuth:
+		return "run orecast login, check token scope with orecast token inspect or ask OreCast administrators for permissions"
+	case exitServer:
+		return "OreCast service failed, please retry later or report it to OreCast administrators"
+	case exitNetwork:
+		return "check service URLs and your network, orecast doctor may help to find the problem"
+	}
+	return ""
+}
+
+// helper function to print error with a hint and exit with exit code of its failure class
+func exitError(err error) {
+	printError(err)
+	code := exitCode(err)
+	if hint := exitHint(code); hint != "" {
+		fmt.Println("HINT", hint)
+	}
+	os.Exit(code)
+}
+
+// helper function to print command usage and exit with usage exit code
+func exitWithUsage(usage func()) {
+	usage()
+	os.Exit(exitUsage)
+}
+
+// helper function to report unsupported command options, print command usage
+// and exit with usage exit code
+func unsupportedOptions(args []string, usage func()) {
+	fmt.Printf("WARNING: unsupported option(s) %+v\n", args)
+	exitWithUsage(usage)
+}
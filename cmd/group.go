@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// helper function to provide usage of group option
+func groupUsage() {
+	fmt.Println("orecast group <ls|add|rm|members|grant|revoke> [value]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# list all groups:")
+	fmt.Println("orecast group ls")
+	fmt.Println("\n# add or remove group (requires admin rights):")
+	fmt.Println("orecast group add site-admin")
+	fmt.Println("orecast group rm site-admin")
+	fmt.Println("\n# list members of the group:")
+	fmt.Println("orecast group members site-admin")
+	fmt.Println("\n# add or remove user from the group (requires admin rights):")
+	fmt.Println("orecast group grant site-admin bob")
+	fmt.Println("orecast group revoke site-admin bob")
+}
+
+// helper function to print status of group request
+func groupStatus(err error, success string) {
+	if err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: %s\n", success)
+}
+
+// helper function to list groups
+func groupList(args []string) {
+	groups, err := orecastClient().ListGroups(cmdContext())
+	if err != nil {
+		exitError(err)
+	}
+	for _, g := range groups {
+		fmt.Println("---")
+		fmt.Printf("Name       : %s\n", g.Name)
+		fmt.Printf("Description: %s\n", g.Description)
+		fmt.Printf("Members    : %s\n", strings.Join(g.Members, ", "))
+	}
+}
+
+// helper function to list members of the group
+func groupMembers(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(groupUsage)
+	}
+	members, err := orecastClient().GroupMembers(cmdContext(), args[1])
+	if err != nil {
+		exitError(err)
+	}
+	for _, m := range members {
+		fmt.Println(m)
+	}
+}
+
+// helper function to add or remove group
+func groupAddDelete(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(groupUsage)
+	}
+	name := args[1]
+	if args[0] == "add" {
+		groupStatus(orecastClient().AddGroup(cmdContext(), name),
+			fmt.Sprintf("group %s was successfully added", name))
+	} else {
+		groupStatus(orecastClient().DeleteGroup(cmdContext(), name),
+			fmt.Sprintf("group %s was successfully removed", name))
+	}
+}
+
+// helper function to grant or revoke group membership
+func groupMembership(args []string) {
+	// args contains [grant|revoke group user]
+	if len(args) != 3 {
+		exitWithUsage(groupUsage)
+	}
+	name, login := args[1], args[2]
+	if args[0] == "grant" {
+		groupStatus(orecastClient().AddGroupMember(cmdContext(), name, login),
+			fmt.Sprintf("user %s was added to group %s", login, name))
+	} else {
+		groupStatus(orecastClient().RemoveGroupMember(cmdContext(), name, login),
+			fmt.Sprintf("user %s was removed from group %s", login, name))
+	}
+}
+
+func groupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "group",
+		Short: "OreCast group command",
+		Long: `OreCast group command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				groupUsage()
+			} else if args[0] == "ls" {
+				groupList(args)
+			} else if args[0] == "members" {
+				groupMembers(args)
+			} else if args[0] == "add" || args[0] == "rm" {
+				groupAddDelete(args)
+			} else if args[0] == "grant" || args[0] == "revoke" {
+				groupMembership(args)
+			} else {
+				unsupportedOptions(args, groupUsage)
+			}
+		},
+	}
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		groupUsage()
+		return nil
+	})
+	return cmd
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// TokenInfo represents decoded token information
+type TokenInfo struct {
+	Header    map[string]any `json:"header"`
+	Claims    map[string]any `json:"claims"`
+	Subject   string         `json:"subject,omitempty"`
+	Login     string         `json:"login,omitempty"`
+	Scope     string         `json:"scope,omitempty"`
+	IssuedAt  *time.Time     `json:"issued_at,omitempty"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+	Remaining string         `json:"remaining,omitempty"`
+	Signature string         `json:"signature"`
+}
+
+// helper function to convert numeric date claim into time
+func claimTime(claims jwt.MapClaims, key string) *time.Time {
+	if val, ok := claims[key].(float64); ok {
+		t := time.Unix(int64(val), 0)
+		return &t
+	}
+	return nil
+}
+
+// helper function to convert claim to a string
+func claimString(claims jwt.MapClaims, key string) string {
+	switch val := claims[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []any:
+		var out []string
+		for _, v := range val {
+			out = append(out, fmt.Sprintf("%v", v))
+		}
+		return strings.Join(out, " ")
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// helper function to decode base64url encoded JSON segment of the token
+func decodeSegment(seg string, val any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, val)
+}
+
+// helper function to decode given token
+func decodeToken(token string, verify bool) (TokenInfo, error) {
+	var info TokenInfo
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return info, errors.New("token contains an invalid number of segments")
+	}
+	header := make(map[string]any)
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return info, fmt.Errorf("unable to decode token header: %w", err)
+	}
+	claims := jwt.MapClaims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return info, fmt.Errorf("unable to decode token payload: %w", err)
+	}
+	info.Header = header
+	info.Claims = claims
+	info.Subject = claimString(claims, "sub")
+	info.Login = claimString(claims, "login")
+	info.Scope = claimString(claims, "scope")
+	info.IssuedAt = claimTime(claims, "iat")
+	info.ExpiresAt = claimTime(claims, "exp")
+	if info.ExpiresAt != nil {
+		if remaining := time.Until(*info.ExpiresAt); remaining > 0 {
+			info.Remaining = remaining.Round(time.Second).String()
+		} else {
+			info.Remaining = "expired"
+		}
+	}
+	info.Signature = "not verified"
+	if verify {
+		if err := validateToken(token); err != nil {
+			info.Signature = fmt.Sprintf("invalid: %v", err)
+		} else if legacyTokenKey(token) {
+			info.Signature = "untrusted: signed with public client id, configure client.token_secret"
+		} else {
+			info.Signature = "valid"
+		}
+	}
+	return info, nil
+}
+
+// helper function to find token to inspect, it can be provided as an
+// argument, via stdin when argument is "-" or taken from cached session
+func inspectedToken(args []string) (string, error) {
+	if len(args) == 2 {
+		if args[1] != "-" {
+			return args[1], nil
+		}
+		line, _ := stdinReader.ReadString('\n')
+		if token := strings.TrimSpace(line); token != "" {
+			return token, nil
+		}
+		return "", errors.New("no token provided via stdin")
+	}
+	if token := os.Getenv("ORECAST_TOKEN"); token != "" {
+		return token, nil
+	}
+	sessions, err := userSessions()
+	if err != nil {
+		return "", errors.New("no token provided and no cached session found, please login first")
+	}
+	for _, s := range sessions {
+		if s.Valid() {
+			return s.AccessToken, nil
+		}
+	}
+	return sessions[0].AccessToken, nil
+}
+
+// helper function to format optional time
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "N/A"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// helper function to inspect token and print its information
+func tokenInspect(args []string, verify, jsonOutput bool) {
+	if len(args) > 2 {
+		fmt.Println("ERROR: wrong number of arguments")
+		exitWithUsage(tokenUsage)
+	}
+	token, err := inspectedToken(args)
+	if err != nil {
+		exitError(err)
+	}
+	info, err := decodeToken(token, verify)
+	if err != nil {
+		exitError(err)
+	}
+	if jsonOutput {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			exitError(err)
+		}
+		fmt.Println(string(data))
+	} else {
+		header, _ := json.Marshal(info.Header)
+		payload, _ := json.MarshalIndent(info.Claims, "", "  ")
+		fmt.Printf("Header     : %s\n", string(header))
+		fmt.Printf("Payload    : %s\n", string(payload))
+		fmt.Printf("Subject    : %s\n", info.Subject)
+		fmt.Printf("Login      : %s\n", info.Login)
+		fmt.Printf("Scope      : %s\n", info.Scope)
+		fmt.Printf("Issued at  : %s\n", formatTime(info.IssuedAt))
+		fmt.Printf("Expires at : %s\n", formatTime(info.ExpiresAt))
+		fmt.Printf("Remaining  : %s\n", info.Remaining)
+		fmt.Printf("Signature  : %s\n", info.Signature)
+	}
+	if verify && info.Signature != "valid" {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	oreClient "github.com/OreCast/client/client"
+	"github.com/spf13/cobra"
+)
+
+// helper function to revoke token in Authz service, the client credentials are
+// sent via HTTP Basic Authorization header as for token requests. The revocation
+// endpoint is optional and we silently skip it if Authz does not provide it
+func revokeToken(token, hint string) error {
+	rurl := fmt.Sprintf("%s/oauth/revoke", _oreConfig.Services.AuthzURL)
+	if verbose > 0 {
+		debugPrintln("HTTP POST", rurl)
+	}
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", hint)
+	req, err := http.NewRequestWithContext(cmdContext(), "POST", rurl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(_oreConfig.Authz.ClientId), url.QueryEscape(_oreConfig.Authz.ClientSecret))
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		if verbose > 0 {
+			fmt.Println("Authz service does not provide revocation endpoint")
+		}
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unable to revoke %s: %w", hint, oreClient.CheckResponse(resp, data))
+	}
+	return nil
+}
+
+// helper function to print session information
+func printSession(s Session) {
+	if s.Context != "" {
+		fmt.Printf("Context    : %s\n", s.Context)
+	}
+	fmt.Printf("Login      : %s\n", s.Login)
+	fmt.Printf("Authz URL  : %s\n", s.AuthzURL)
+	fmt.Printf("Client ID  : %s\n", s.ClientId)
+	fmt.Printf("Scope      : %s\n", s.Scope)
+	if s.Grant != "" {
+		fmt.Printf("Grant      : %s\n", s.Grant)
+	}
+	fmt.Printf("Expires    : %s\n", time.Unix(s.Expires, 0).Format(time.RFC3339))
+}
+
+func loginCommand() *cobra.Command {
+	var scope string
+	var device, browser, noBrowser bool
+	var callbackTimeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "OreCast login command",
+		Long: `OreCast login command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var session Session
+			var err error
+			if device {
+				session, err = deviceSession(scope)
+			} else if browser {
+				session, err = browserSession(scope, callbackTimeout, noBrowser)
+			} else {
+				session, err = newSession(scope)
+			}
+			if err != nil {
+				exitError(err)
+			}
+			fmt.Printf("SUCCESS: logged in as %s with %s scope\n", session.Login, session.Scope)
+			if session.Expires > 0 {
+				fmt.Printf("session expires at %s\n", time.Unix(session.Expires, 0).Format(time.RFC3339))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&scope, "scope", scopeRead, "token scope: read, write or admin")
+	cmd.Flags().BoolVar(&device, "device", false, "login using device authorization flow on machines without browser")
+	cmd.Flags().BoolVar(&browser, "browser", false, "login using authorization code flow with PKCE in a browser")
+	cmd.Flags().BoolVar(&noBrowser, "no-browser", false, "print authorization URL instead of opening a browser")
+	cmd.Flags().DurationVar(&callbackTimeout, "callback-timeout", defaultCallbackTimeout, "time to wait for browser authorization")
+	return cmd
+}
+
+func logoutCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "OreCast logout command",
+		Long: `OreCast logout command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sessions, err := contextSessions()
+			if err != nil {
+				fmt.Println("Not logged in")
+				return
+			}
+			for _, session := range sessions {
+				addSecret(session.AccessToken)
+				addSecret(session.RefreshToken)
+				if err := revokeToken(session.AccessToken, "access_token"); err != nil {
+					fmt.Println("WARNING:", redact(err.Error()))
+				}
+				if session.RefreshToken == "" {
+					continue
+				}
+				if err := revokeToken(session.RefreshToken, "refresh_token"); err != nil {
+					fmt.Println("WARNING:", redact(err.Error()))
+				}
+			}
+			if err := deleteSessions(); err != nil {
+				exitError(err)
+			}
+			fmt.Printf("SUCCESS: %s logged out from %s\n", sessions[0].Login, sessions[0].AuthzURL)
+		},
+	}
+	return cmd
+}
+
+func statusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "OreCast session status command",
+		Long: `OreCast session status command
+                Complete documentation is available at https://orecast.com/documentation/`,
+		Run: func(cmd *cobra.Command, args []string) {
+			sessions, err := userSessions()
+			if err != nil {
+				fmt.Printf("Not logged in to %s\n", _oreConfig.Services.AuthzURL)
+				os.Exit(1)
+			}
+			active := false
+			for _, session := range sessions {
+				fmt.Println("---")
+				printSession(session)
+				if session.Valid() {
+					active = true
+					remaining := time.Until(time.Unix(session.Expires, 0)).Round(time.Second)
+					fmt.Printf("Status     : active, expires in %s\n", remaining)
+				} else {
+					fmt.Println("Status     : expired")
+				}
+			}
+			if !active {
+				os.Exit(1)
+			}
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Used for MFA flags.
+var otpCode string
+
+// maxOTPAttempts defines how many times user can enter one-time code interactively
+const maxOTPAttempts = 3
+
+// MFA errors reported by Authz service
+var (
+	errAccountLocked = errors.New("account is locked due to too many failed attempts, please contact OreCast administrators")
+	errInvalidOTP    = errors.New("invalid one-time code")
+)
+
+// AuthorizeResponse represents response of Authz authorize endpoint, when
+// second authentication factor is required it contains MFA challenge
+type AuthorizeResponse struct {
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	Challenge     string `json:"challenge,omitempty"`
+	ChallengeType string `json:"challenge_type,omitempty"`
+}
+
+// helper function to send user credentials to Authz authorize endpoint
+func authorizeRequest(user User) (AuthorizeResponse, int, error) {
+	var response AuthorizeResponse
+	rurl := fmt.Sprintf(
+		"%s/oauth/authorize?client_id=%s&response_type=code",
+		_oreConfig.Services.AuthzURL,
+		_oreConfig.Authz.ClientId)
+	if verbose > 0 {
+		debugPrintln("HTTP POST", rurl)
+	}
+	data, err := json.Marshal(user)
+	if err != nil {
+		return response, 0, err
+	}
+	resp, err := httpPost(rurl, "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return response, 0, err
+	}
+	defer resp.Body.Close()
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return response, resp.StatusCode, err
+	}
+	if verbose > 1 {
+		debugPrintln("## authorize response", string(data))
+	}
+	err = json.Unmarshal(data, &response)
+	return response, resp.StatusCode, err
+}
+
+// helper function to classify Authz authorize response error
+func authorizeError(user User, response AuthorizeResponse, status int) error {
+	switch {
+	case status == http.StatusLocked || response.Error == "account_locked" || response.Status == "locked":
+		return errAccountLocked
+	case response.Error == "invalid_otp" || response.Error == "invalid_code":
+		return errInvalidOTP
+	}
+	return fmt.Errorf("No user %s found in Authz service", user.Login)
+}
+
+// helper function to obtain one-time code from --otp flag or ORECAST_OTP
+// environment variable, it returns false if code should be prompted
+func providedOTP() (string, bool) {
+	if otpCode != "" {
+		return otpCode, true
+	}
+	if code := os.Getenv("ORECAST_OTP"); code != "" {
+		return code, true
+	}
+	return "", false
+}
+
+// helper function to authorize user in Authz service, if Authz service
+// requests second authentication factor the user is asked for TOTP code
+func authorizeUser(user User) error {
+	response, status, err := authorizeRequest(user)
+	if err != nil {
+		return err
+	}
+	if response.Status == "ok" {
+		return nil
+	}
+	if response.Status != "mfa_required" {
+		return authorizeError(user, response, status)
+	}
+	code, provided := providedOTP()
+	for attempt := 1; attempt <= maxOTPAttempts; attempt++ {
+		if !provided {
+			code = inputPrompt("OreCast one-time code:")
+		}
+		if code == "" {
+			return errors.New("no one-time code provided, please use --otp flag or ORECAST_OTP environment variable")
+		}
+		user.OTP = code
+		user.Challenge = response.Challenge
+		response, status, err = authorizeRequest(user)
+		if err != nil {
+			return err
+		}
+		if response.Status == "ok" {
+			return nil
+		}
+		err = authorizeError(user, response, status)
+		if !errors.Is(err, errInvalidOTP) || provided {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Invalid one-time code, please try again")
+		if response.Challenge == "" {
+			// Authz service did not issue new challenge, keep the current one
+			response.Challenge = user.Challenge
+		}
+	}
+	return errInvalidOTP
+}
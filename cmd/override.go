@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// configOverride describes environment variable and flag which override
+// configuration key
+type configOverride struct {
+	Key   string // dotted configuration key
+	Env   string // environment variable name
+	Flag  string // global flag name
+	Usage string // flag usage
+}
+
+// configOverrides lists all configuration keys which can be overridden, the
+// precedence order is flag > environment > discovery > project file > context
+// > user file > defaults
+var configOverrides = []configOverride{
+	{"services.frontend_url", "ORECAST_FRONTEND_URL", "frontend-url", "Frontend service URL"},
+	{"services.discovery_url", "ORECAST_DISCOVERY_URL", "discovery-url", "Discovery service URL"},
+	{"services.metadata_url", "ORECAST_METADATA_URL", "metadata-url", "MetaData service URL"},
+	{"services.datamanagement_url", "ORECAST_DATAMANAGEMENT_URL", "datamanagement-url", "DataManagement service URL"},
+	{"services.databookkeeping_url", "ORECAST_DATABOOKKEEPING_URL", "databookkeeping-url", "DataBookkeeping service URL"},
+	{"services.authz_url", "ORECAST_AUTHZ_URL", "authz-url", "Authz service URL"},
+	{"authz.client_id", "ORECAST_CLIENT_ID", "client-id", "Authz client id"},
+	{"authz.client_secret", "ORECAST_CLIENT_SECRET", "client-secret", "Authz client secret (prefer ORECAST_CLIENT_SECRET to keep it out of shell history)"},
+	{"client.token_secret", "ORECAST_TOKEN_SECRET", "token-secret", "HS256 shared secret to verify tokens"},
+	{"client.token_public_key", "ORECAST_TOKEN_PUBLIC_KEY", "token-public-key", "PEM public key file to verify RS256/ES256 tokens"},
+	{"client.jwks_url", "ORECAST_JWKS_URL", "jwks-url", "JWKS endpoint of Authz service"},
+	{"client.refresh_window", "ORECAST_REFRESH_WINDOW", "refresh-window", "refresh tokens expiring within this window, e.g. 5m"},
+	{"client.api_key", "ORECAST_API_KEY", "api-key", "API key used instead of user credentials"},
+	{"client.bootstrap", "ORECAST_BOOTSTRAP", "bootstrap", "bootstrap service URLs from Discovery service, true or false"},
+	{"client.discovery_ttl", "ORECAST_DISCOVERY_TTL", "discovery-ttl", "cache discovered service URLs for this duration, e.g. 1h"},
+	{"client.ca_bundle", "ORECAST_CA_BUNDLE", "ca-bundle", "PEM file with CA certificates of OreCast services"},
+	{"client.client_cert", "ORECAST_CLIENT_CERT", "client-cert", "PEM client certificate for mutual TLS"},
+	{"client.client_key", "ORECAST_CLIENT_KEY", "client-key", "PEM client key for mutual TLS"},
+	{"client.proxy", "ORECAST_PROXY", "proxy", "proxy URL of all HTTP requests, default is HTTPS_PROXY/HTTP_PROXY"},
+	{"client.insecure_skip_verify", "ORECAST_INSECURE_SKIP_VERIFY", "insecure-skip-verify", "do not verify server certificates, INSECURE"},
+	{"client.legacy_token_key", "ORECAST_LEGACY_TOKEN_KEY", "legacy-token-key", "accept HS256 tokens signed with public client id of legacy Authz services, INSECURE"},
+}
+
+// boolOverrides lists flags of boolean configuration keys which can be given without value
+var boolOverrides = map[string]bool{
+	"bootstrap":            true,
+	"insecure-skip-verify": true,
+	"legacy-token-key":     true,
+}
+
+// helper function to register global flags of configuration overrides
+func addOverrideFlags() {
+	for _, o := range configOverrides {
+		rootCmd.PersistentFlags().String(o.Flag, "", fmt.Sprintf("%s, overrides %s and %s", o.Usage, o.Env, o.Key))
+		if boolOverrides[o.Flag] {
+			rootCmd.PersistentFlags().Lookup(o.Flag).NoOptDefVal = "true"
+		}
+	}
+}
+
+// helper function to bind environment variables and flags to configuration keys
+func bindOverrides() error {
+	for _, o := range configOverrides {
+		if err := viper.BindEnv(o.Key, o.Env); err != nil {
+			return err
+		}
+		if err := viper.BindPFlag(o.Key, rootCmd.PersistentFlags().Lookup(o.Flag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper function to describe source of configuration key value
+func overrideSource(o configOverride) string {
+	if f := rootCmd.PersistentFlags().Lookup(o.Flag); f != nil && f.Changed {
+		return "flag --" + o.Flag
+	}
+	if _, ok := os.LookupEnv(o.Env); ok {
+		return "env " + o.Env
+	}
+	if _discovered[o.Key] {
+		return "discovery " + viper.GetString("services.discovery_url")
+	}
+	if _projectConfig != nil && projectKey(o.Key) && _projectConfig.InConfig(o.Key) {
+		return "project file " + _projectConfig.ConfigFileUsed()
+	}
+	if name := currentContext(); name != "" {
+		if viper.InConfig("contexts." + name + "." + o.Key) {
+			return "context " + name
+		}
+		if contextKey(o.Key) {
+			return "default"
+		}
+	}
+	if viper.InConfig(o.Key) {
+		return "user file"
+	}
+	return "default"
+}
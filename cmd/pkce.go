@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// defaultCallbackTimeout defines how long we wait for authorization code
+const defaultCallbackTimeout = 2 * time.Minute
+
+// authorization code received by loopback callback server
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// helper function to generate random base64url encoded string
+func randomString(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// helper function to derive PKCE S256 code challenge from code verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// helper function to open given URL in user's browser
+func openBrowser(rurl string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", rurl)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", rurl)
+	default:
+		cmd = exec.Command("xdg-open", rurl)
+	}
+	return cmd.Start()
+}
+
+// helper function to check nonce claim of the token, the token signature is
+// verified before its claims are trusted. The nonce claim must be present
+// when required, e.g. in id_token, otherwise it is only checked if present.
+func checkNonce(token, nonce string, required bool) error {
+	claims := jwt.MapClaims{}
+	if err := parseToken(token, claims); err != nil {
+		return err
+	}
+	val, ok := claims["nonce"]
+	if !ok && !required {
+		return nil
+	}
+	if !ok {
+		return errors.New("token has no nonce of authorization request")
+	}
+	if val != nonce {
+		return errors.New("token nonce does not match authorization request")
+	}
+	return nil
+}
+
+// helper function to start loopback callback server which receives
+// authorization code from Authz service
+func callbackServer(listener net.Listener, state string, results chan<- callbackResult) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			// not a response to our authorization request, e.g. stray local
+			// request, keep waiting for the actual callback
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, "authorization response state does not match request")
+			return
+		}
+		var res callbackResult
+		if e := query.Get("error"); e != "" {
+			res.err = &OAuthError{Code: e, Description: query.Get("error_description")}
+		} else if res.code = query.Get("code"); res.code == "" {
+			res.err = errors.New("no authorization code in Authz response")
+		}
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, "OreCast login failed, please return to your terminal.")
+		} else {
+			fmt.Fprintln(w, "OreCast login succeeded, you may close this window.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(listener)
+	return srv
+}
+
+// helper function to login using OAuth2 authorization code flow with PKCE
+func browserSession(scope string, timeout time.Duration, noBrowser bool) (Session, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return Session{}, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return Session{}, err
+	}
+	nonce, err := randomString(16)
+	if err != nil {
+		return Session{}, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return Session{}, err
+	}
+	redirectURI := fmt.Sprintf("http://%s/callback", listener.Addr().String())
+	results := make(chan callbackResult, 1)
+	srv := callbackServer(listener, state, results)
+	defer srv.Shutdown(context.Background())
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", _oreConfig.Authz.ClientId)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", scope)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+	rurl := fmt.Sprintf("%s/oauth/authorize?%s", _oreConfig.Services.AuthzURL, params.Encode())
+	fmt.Printf("To login please visit:\n\n    %s\n\n", rurl)
+	if !noBrowser {
+		if err := openBrowser(rurl); err != nil && verbose > 0 {
+			fmt.Println("WARNING: unable to open browser", err)
+		}
+	}
+	fmt.Println("Waiting for authorization...")
+
+	if timeout <= 0 {
+		timeout = defaultCallbackTimeout
+	}
+	var res callbackResult
+	select {
+	case res = <-results:
+	case <-cmdContext().Done():
+		return Session{}, cmdContext().Err()
+	case <-time.After(timeout):
+		return Session{}, fmt.Errorf("no authorization response received within %s", timeout)
+	}
+	if res.err != nil {
+		return Session{}, res.err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", res.code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", _oreConfig.Authz.ClientId)
+	form.Set("code_verifier", verifier)
+	token, err := tokenRequest(form)
+	if err != nil {
+		return Session{}, err
+	}
+	if err := validateToken(token.AccessToken); err != nil {
+		return Session{}, err
+	}
+	if token.IDToken != "" {
+		if err := checkNonce(token.IDToken, nonce, true); err != nil {
+			return Session{}, fmt.Errorf("invalid id_token: %w", err)
+		}
+	}
+	if err := checkNonce(token.AccessToken, nonce, false); err != nil {
+		return Session{}, err
+	}
+	session := Session{
+		Login:        tokenLogin(token.AccessToken),
+		Scope:        scope,
+		Grant:        browserGrant,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+	return storeSession(session), nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// TestCallbackServer tests that only responses with state of authorization
+// request complete browser login
+func TestCallbackServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(chan callbackResult, 1)
+	srv := callbackServer(listener, "state123", results)
+	defer srv.Close()
+	base := "http://" + listener.Addr().String()
+
+	stray := []string{
+		"/callback",
+		"/callback?code=abc",
+		"/callback?state=other&code=abc",
+		"/callback?state=other&error=access_denied",
+		"/favicon.ico",
+	}
+	for _, path := range stray {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < http.StatusBadRequest {
+			t.Errorf("%s: status %d, want client error", path, resp.StatusCode)
+		}
+		select {
+		case res := <-results:
+			t.Fatalf("%s: stray request completed login with %+v", path, res)
+		default:
+		}
+	}
+
+	resp, err := http.Get(base + "/callback?state=state123&code=abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	select {
+	case res := <-results:
+		if res.err != nil || res.code != "abc" {
+			t.Errorf("unexpected result %+v", res)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("callback did not complete login")
+	}
+}
+
+// TestCallbackServerError tests that error response of Authz service fails login
+func TestCallbackServerError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(chan callbackResult, 1)
+	srv := callbackServer(listener, "state123", results)
+	defer srv.Close()
+	resp, err := http.Get("http://" + listener.Addr().String() + "/callback?state=state123&error=access_denied")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if res := <-results; res.err == nil {
+		t.Error("expected login error")
+	}
+}
+
+// TestCheckNonce tests nonce check of verified tokens
+func TestCheckNonce(t *testing.T) {
+	setVerifyConfig(t, ClientConfig{TokenSecret: "tsecret"}, "client_id")
+	sign := func(key string, claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	tests := []struct {
+		name     string
+		token    string
+		required bool
+		wantErr  bool
+	}{
+		{"matching nonce", sign("tsecret", jwt.MapClaims{"nonce": "n1"}), true, false},
+		{"mismatched nonce", sign("tsecret", jwt.MapClaims{"nonce": "n2"}), true, true},
+		{"missing required nonce", sign("tsecret", jwt.MapClaims{}), true, true},
+		{"missing optional nonce", sign("tsecret", jwt.MapClaims{}), false, false},
+		{"mismatched optional nonce", sign("tsecret", jwt.MapClaims{"nonce": "n2"}), false, true},
+		{"forged token", sign("other", jwt.MapClaims{"nonce": "n1"}), true, true},
+		{"malformed token", "not-a-token", false, true},
+	}
+	for _, tt := range tests {
+		if err := checkNonce(tt.token, "n1", tt.required); (err != nil) != tt.wantErr {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+	}
+}
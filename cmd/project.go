@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// projectConfigName defines name of per-project configuration file
+const projectConfigName = ".orecast.yaml"
+
+// ProjectDefaults represents project defaults used by commands when
+// corresponding arguments are omitted
+type ProjectDefaults struct {
+	Site          string `mapstructure:"site"`           // default site name
+	Bucket        string `mapstructure:"bucket"`         // default bucket name
+	DatasetPrefix string `mapstructure:"dataset_prefix"` // default dataset prefix
+}
+
+// project defaults
+var _defaults ProjectDefaults
+
+// project configuration in use
+var _projectConfig *viper.Viper
+
+// projectKeys lists configuration keys which project file may set, project
+// files come with cloned repositories and therefore can't change service URLs,
+// credentials, TLS or proxy settings where user password and tokens are sent
+var projectKeys = []string{
+	"defaults",
+	"current_context",
+	"client.refresh_window",
+	"client.discovery_ttl",
+}
+
+// helper function to check if configuration key can be set by project file
+func projectKey(key string) bool {
+	for _, k := range projectKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to find project configuration file in current directory
+// and its parents, the user configuration file is skipped
+func findProjectConfig(userFile string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	userFile, _ = filepath.Abs(userFile)
+	for {
+		fname := filepath.Join(dir, projectConfigName)
+		if fname != userFile {
+			if info, err := os.Stat(fname); err == nil && !info.IsDir() {
+				return fname
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// helper function to read project configuration, it is merged over user
+// configuration by mergeProjectConfig after context is applied
+func readProjectConfig(fname string) error {
+	v := viper.New()
+	v.SetConfigFile(fname)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("unable to parse %s, error %v", fname, err)
+	}
+	_projectConfig = v
+	return nil
+}
+
+// helper function to merge project configuration over user configuration,
+// keys which project file is not allowed to set are ignored with a warning
+func mergeProjectConfig() error {
+	if _projectConfig == nil {
+		return nil
+	}
+	fname := _projectConfig.ConfigFileUsed()
+	if verbose > 0 {
+		fmt.Println("merge project config", fname)
+	}
+	settings := make(map[string]any)
+	var ignored []string
+	for _, key := range _projectConfig.AllKeys() {
+		if projectKey(key) {
+			setNested(settings, key, _projectConfig.Get(key))
+		} else {
+			ignored = append(ignored, key)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		fmt.Printf("WARNING: ignore %s in project file %s, it may only set %s\n",
+			strings.Join(ignored, ", "), fname, strings.Join(projectKeys, ", "))
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// helper function to read project defaults
+func parseProjectDefaults() error {
+	var defaults ProjectDefaults
+	if err := viper.UnmarshalKey("defaults", &defaults); err != nil {
+		return err
+	}
+	_defaults = defaults
+	return nil
+}
+
+// helper function to return default bucket in site/bucket form
+func defaultBucket() string {
+	if _defaults.Bucket == "" {
+		return ""
+	}
+	if strings.Contains(_defaults.Bucket, "/") || _defaults.Site == "" {
+		return _defaults.Bucket
+	}
+	return fmt.Sprintf("%s/%s", _defaults.Site, _defaults.Bucket)
+}
+
+// helper function to insert default bucket into s3 command arguments when
+// bucket argument is omitted, e.g. [upload file.txt] becomes
+// [upload site/bucket file.txt]
+func withDefaultBucket(args []string, nargs int) []string {
+	bucket := defaultBucket()
+	if len(args) != nargs-1 || bucket == "" {
+		return args
+	}
+	if verbose > 0 {
+		fmt.Println("use default bucket", bucket)
+	}
+	out := []string{args[0], bucket}
+	return append(out, args[1:]...)
+}
+
+// helper function to get user input which falls back to project default
+func projectPrompt(label, value string) string {
+	if value == "" {
+		return inputPrompt(label)
+	}
+	return defaultPrompt(strings.TrimSuffix(label, ":"), value)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactedValue is used in place of sensitive values in printed output
+const redactedValue = "***"
+
+// secrets holds sensitive values known to the client at run time, e.g.
+// passwords, tokens and client secrets, which should never be printed
+var (
+	secrets      []string
+	secretsMutex sync.RWMutex
+)
+
+// secretPatterns matches sensitive values in URLs, form data, JSON payloads,
+// struct dumps and HTTP headers, key names which end URL path, e.g.
+// "/oauth/token": in URL errors, are not keys of sensitive values
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)((?:^|[^/])(?:access_token|refresh_token|id_token|client_secret|access_secret|accesssecret|clientsecret|password|passwd|secret|api_key|token)"?\s*[:=]\s*"?)([^"&\s,}]+)`),
+	regexp.MustCompile(`(?i)((?:Bearer|Basic)\s+)([A-Za-z0-9\-._~+/]+=*)`),
+	regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+}
+
+// helper function to register sensitive value which should be redacted from output
+func addSecret(secret string) {
+	if len(secret) < 4 {
+		// too short values would redact unrelated output
+		return
+	}
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	secrets = append(secrets, secret)
+}
+
+// helper function to register secrets from OreCast configuration
+func addConfigSecrets() {
+	addSecret(_oreConfig.Authz.ClientSecret)
+	addSecret(_oreConfig.Authz.Encryption.Secret)
+	addSecret(_oreConfig.Encryption.Secret)
+	addSecret(_clientConfig.TokenSecret)
+	addSecret(_clientConfig.APIKey)
+}
+
+// helper function to redact sensitive information from given string
+func redact(s string) string {
+	secretsMutex.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	secretsMutex.RUnlock()
+	for idx, pat := range secretPatterns {
+		if idx == len(secretPatterns)-1 {
+			s = pat.ReplaceAllString(s, redactedValue)
+		} else {
+			s = pat.ReplaceAllString(s, "${1}"+redactedValue)
+		}
+	}
+	return s
+}
+
+// helper function to print error message with sensitive information redacted
+func printError(args ...any) {
+	if interrupted() {
+		fmt.Println("ERROR interrupted")
+		return
+	}
+	args = append([]any{"ERROR"}, args...)
+	fmt.Println(redact(strings.TrimSuffix(fmt.Sprintln(args...), "\n")))
+}
+
+// helper function to print verbose message with sensitive information redacted
+func debugPrintln(args ...any) {
+	fmt.Println(redact(strings.TrimSuffix(fmt.Sprintln(args...), "\n")))
+}
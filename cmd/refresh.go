@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// defaultRefreshWindow defines default time window before token expiration
+// when client refreshes the token
+const defaultRefreshWindow = 5 * time.Minute
+
+// helper function to return configured token refresh window
+func refreshWindow() time.Duration {
+	if _clientConfig.RefreshWindow > 0 {
+		return _clientConfig.RefreshWindow
+	}
+	return defaultRefreshWindow
+}
+
+// helper function to obtain new access token using session refresh token,
+// the refreshed session is stored in token cache
+func refreshSession(s Session) (Session, error) {
+	if s.RefreshToken == "" {
+		return s, errors.New("no refresh token in session")
+	}
+	if verbose > 0 {
+		fmt.Println("refresh token of", s.Login, "with scope", s.Scope)
+	}
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", s.RefreshToken)
+	if s.Scope != "" {
+		form.Set("scope", s.Scope)
+	}
+	token, err := tokenRequest(form)
+	if err != nil {
+		return s, err
+	}
+	if err := validateToken(token.AccessToken); err != nil {
+		return s, err
+	}
+	s.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		// Authz service may rotate refresh tokens
+		s.RefreshToken = token.RefreshToken
+	}
+	return storeSession(s), nil
+}
+
+// helper function to obtain token of given scope using refresh token of a
+// cached session of another scope, e.g. write token after login with read
+// scope, Authz service rejects the request if refresh token does not allow it
+func scopeSession(scope string) (Session, error) {
+	sessions, err := userSessions()
+	if err != nil {
+		return Session{}, err
+	}
+	for _, s := range sessions {
+		if s.RefreshToken == "" || s.Scope == scope {
+			continue
+		}
+		ns := s
+		ns.Scope = scope
+		ns, err = refreshSession(ns)
+		if err != nil {
+			if verbose > 0 {
+				fmt.Println("unable to obtain", scope, "scope with refresh token of", s.Scope, "scope:", redact(err.Error()))
+			}
+			continue
+		}
+		if ns.RefreshToken != s.RefreshToken {
+			// rotated refresh token replaces the one of original session
+			s.RefreshToken = ns.RefreshToken
+			storeSession(s)
+		}
+		return ns, nil
+	}
+	return Session{}, fmt.Errorf("no cached session can obtain token with %s scope", scope)
+}
+
+// helper function to renew access token of given scope after it was rejected
+// by OreCast service, it returns new token if session can be refreshed
+func renewToken(scope string) (string, error) {
+	for _, s := range coveringSessions(scope) {
+		if s.RefreshToken == "" {
+			continue
+		}
+		if s, err := refreshSession(s); err == nil {
+			return s.AccessToken, nil
+		}
+	}
+	s, err := scopeSession(scope)
+	return s.AccessToken, err
+}
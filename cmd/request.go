@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+
+	oreClient "github.com/OreCast/client/client"
+)
+
+// cliTokens provides tokens to OreCast client from environment, token cache,
+// API key or user credentials
+type cliTokens struct{}
+
+// Token implements oreClient.TokenSource interface
+func (cliTokens) Token(ctx context.Context, scope string) (string, error) {
+	return accessToken(scope)
+}
+
+// RenewToken implements oreClient.TokenRenewer interface, during replay it
+// returns redacted token such that recorded 401 response is followed by
+// replay of the retried request
+func (cliTokens) RenewToken(ctx context.Context, scope string) (string, error) {
+	if replaying() {
+		return redactedValue, nil
+	}
+	return renewToken(scope)
+}
+
+// helper function to create OreCast client from configuration
+func orecastClient() *oreClient.Client {
+	c := oreClient.New(_oreConfig.Services, cliTokens{})
+	c.HTTPClient = httpClient()
+	if verbose > 0 {
+		c.Debug = debugPrintln
+	}
+	return c
+}
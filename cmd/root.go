@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
+	oreClient "github.com/OreCast/client/client"
 	oreConfig "github.com/OreCast/common/config"
 	"github.com/spf13/cobra"
 )
@@ -23,7 +21,8 @@ var (
 
 // Execute executes the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	_ctx = interruptContext()
+	return rootCmd.ExecuteContext(_ctx)
 }
 
 // orecast configuration
@@ -34,6 +33,14 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.orecast.yaml)")
 	rootCmd.PersistentFlags().IntVar(&verbose, "verbose", 0, "verbosity level)")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "name of the context from config file to use")
+	rootCmd.PersistentFlags().BoolVar(&passwordStdin, "password-stdin", false, "read OreCast password from stdin")
+	rootCmd.PersistentFlags().StringVar(&passwordFile, "password-file", "", "read OreCast password from given file")
+	rootCmd.PersistentFlags().StringVar(&otpCode, "otp", "", "one-time code of second authentication factor")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", oreClient.DefaultTimeout, "connect and read timeout of every HTTP request attempt")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record HTTP requests and responses with redacted secrets into given cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "replay HTTP responses from given cassette file instead of OreCast services")
+	addOverrideFlags()
 
 	rootCmd.AddCommand(metaCommand())
 	rootCmd.AddCommand(dbsCommand())
@@ -41,13 +48,29 @@ func init() {
 	rootCmd.AddCommand(authCommand())
 	rootCmd.AddCommand(s3Command())
 	rootCmd.AddCommand(userCommand())
+	rootCmd.AddCommand(loginCommand())
+	rootCmd.AddCommand(logoutCommand())
+	rootCmd.AddCommand(statusCommand())
+	rootCmd.AddCommand(apikeyCommand())
+	rootCmd.AddCommand(groupCommand())
+	rootCmd.AddCommand(contextCommand())
+	rootCmd.AddCommand(configCommand())
+	rootCmd.AddCommand(doctorCommand())
 }
 
 func initConfig() {
-	config, err := oreConfig.ParseConfig(cfgFile)
+	config, err := parseConfig(cfgFile)
 	if err != nil {
-		fmt.Println("ERROR", err)
-		os.Exit(1)
+		exitError(err)
+	}
+	if err := parseClientConfig(); err != nil {
+		exitError(err)
+	}
+	if err := setupCassette(); err != nil {
+		exitError(err)
 	}
+	setupHTTPClient()
+	bootstrapServices(&config)
 	_oreConfig = &config
+	addConfigSecrets()
 }
@@ -1,32 +1,20 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 
+	oreClient "github.com/OreCast/client/client"
 	"github.com/spf13/cobra"
 )
 
 // StorageRecord represents Storage record returned by datamanagement service
-type StorageRecord struct {
-	Status string `json:"status"`
-	Data   any    `json:"data"`
-}
+type StorageRecord = oreClient.StorageRecord
 
 // UploadRecord represents Storage record returned by datamanagement service
-type UploadRecord struct {
-	Status string `json:"status"`
-	Error  string `json:"error"`
-	Msg    string `json:"msg"`
-	Object any    `json:"object"`
-}
+type UploadRecord = oreClient.UploadRecord
 
 // helper function to provide s3 usage info
 func s3Usage() {
@@ -44,6 +32,8 @@ func s3Usage() {
 	fmt.Println("orecast s3 ls Cornell")
 	fmt.Println("\n# list specific bucket on s3 storage:")
 	fmt.Println("orecast s3 ls Cornell/bucket")
+	fmt.Println("\n# upload file to default bucket of the project .orecast.yaml:")
+	fmt.Println("orecast s3 upload file.txt")
 }
 
 // helper function to list content of a bucket on s3 storage
@@ -51,30 +41,17 @@ func s3List(args []string) {
 	// args contains [ls bucket]
 	if len(args) != 2 {
 		fmt.Println("ERROR: wrong number of arguments")
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	if args[0] != "ls" {
 		fmt.Println("ERROR: wrong action", args)
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	bucketName := args[1]
 	fmt.Printf("INFO: list bucket %s\n", bucketName)
-
-	var results StorageRecord
-	rurl := fmt.Sprintf("%s/storage/%s", _oreConfig.Services.DataManagementURL, bucketName)
-	if verbose > 0 {
-		fmt.Println("HTTP GET", rurl)
-	}
-	resp, err := http.Get(rurl)
+	results, err := orecastClient().ListBucket(cmdContext(), bucketName)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-	defer resp.Body.Close()
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&results); err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		exitError(err)
 	}
 	fmt.Printf("results: %+v\n", results)
 }
@@ -84,29 +61,17 @@ func s3Create(args []string) {
 	// args contains [create bucket]
 	if len(args) != 2 {
 		fmt.Println("ERROR: wrong number of arguments")
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	if args[0] != "create" {
 		fmt.Println("ERROR: wrong action", args)
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	bucketName := args[1]
 	fmt.Printf("INFO: create bucket %s\n", bucketName)
-	var results StorageRecord
-	rurl := fmt.Sprintf("%s/storage/%s", _oreConfig.Services.DataManagementURL, bucketName)
-	if verbose > 0 {
-		fmt.Println("HTTP POST", rurl)
-	}
-	resp, err := http.Post(rurl, "", nil)
+	results, err := orecastClient().CreateBucket(cmdContext(), bucketName)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-	defer resp.Body.Close()
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&results); err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		exitError(err)
 	}
 	fmt.Printf("results: %+v\n", results)
 }
@@ -127,92 +92,35 @@ func s3Upload(args []string) {
 	// args contains [upload bucket file|dir]
 	if len(args) != 3 {
 		fmt.Println("ERROR: wrong number of arguments")
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	if args[0] != "upload" {
 		fmt.Println("ERROR: wrong action", args)
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	bucketName := args[1]
 	fobj := args[2]
 	var files []string
 	isDir, err := isDirectory(fobj)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		exitError(err)
 	}
 	if isDir {
 		if dirFiles, err := ioutil.ReadDir(fobj); err == nil {
 			for _, file := range dirFiles {
 				if !file.IsDir() {
-					files = append(files, file.Name())
+					files = append(files, filepath.Join(fobj, file.Name()))
 				}
 			}
 		}
 	} else {
 		files = append(files, fobj)
 	}
-	for _, f := range files {
-		fname := filepath.Join(fobj, f)
+	for _, fname := range files {
 		fmt.Printf("INFO: upload %s to bucket %s\n", fname, bucketName)
-		rurl := fmt.Sprintf("%s/storage/%s/%s", _oreConfig.Services.DataManagementURL, bucketName, f)
-		if verbose > 0 {
-			fmt.Println("HTTP POST", rurl)
-		}
-		// open file and read its content
-		// TODO: we may need buffer stream to reduce RAM utilization
-		file, err := os.Open(fname)
-		if err != nil {
-			fmt.Println("ERROR", err)
-			os.Exit(1)
-		}
-		defer file.Close()
-
-		// send POST request to DataManagement service with file data content
-		// see https://stackoverflow.com/questions/20205796/post-data-using-the-content-type-multipart-form-data
-		/*
-		   ```
-		    curl -X POST http://localhost:8340/storage/cornell/s3-bucket/archive.zip \
-		     -F "file=@/path/test.zip" \
-		     -H "Content-Type: multipart/form-data"
-		   ```
-		*/
-		// prepare our payload by reading the local file and passing it to
-		// multipart writer
-		var buf bytes.Buffer
-		var errBuf error
-		w := multipart.NewWriter(&buf)
-		if fw, err := w.CreateFormFile("file", file.Name()); err == nil {
-			if _, err := io.Copy(fw, file); err != nil {
-				errBuf = err
-			}
-		} else {
-			errBuf = err
-		}
-		w.Close()
-		if errBuf != nil {
-			fmt.Println("ERROR:", errBuf)
-			os.Exit(1)
-		}
-
-		req, err := http.NewRequest("POST", rurl, &buf)
+		results, err := orecastClient().Upload(cmdContext(), bucketName, fname)
 		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
-		req.Header.Set("Content-Type", w.FormDataContentType())
-		client := http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
-		defer resp.Body.Close()
-		dec := json.NewDecoder(resp.Body)
-		var results UploadRecord
-		if err := dec.Decode(&results); err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
+			exitError(err)
 		}
 		fmt.Printf("results: %+v\n", results)
 	}
@@ -223,39 +131,17 @@ func s3Delete(args []string) {
 	// args contains [delete bucket]
 	if len(args) != 2 {
 		fmt.Println("ERROR: wrong number of arguments")
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	if args[0] != "delete" {
 		fmt.Println("ERROR: wrong action", args)
-		os.Exit(1)
+		exitWithUsage(s3Usage)
 	}
 	bucketName := args[1]
 	fmt.Printf("INFO: delete bucket %s\n", bucketName)
-	var results StorageRecord
-	rurl := fmt.Sprintf("%s/storage/%s", _oreConfig.Services.DataManagementURL, bucketName)
-	if verbose > 0 {
-		fmt.Println("HTTP DELETE", rurl)
-	}
-	req, err := http.NewRequest("DELETE", rurl, nil)
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-	client := http.Client{}
-	resp, err := client.Do(req)
+	results, err := orecastClient().DeleteBucket(cmdContext(), bucketName)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-	defer resp.Body.Close()
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&results); err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		exitError(err)
 	}
 	fmt.Printf("results: %+v\n", results)
 }
@@ -271,15 +157,15 @@ func s3Command() *cobra.Command {
 			if len(args) == 0 {
 				s3Usage()
 			} else if args[0] == "ls" {
-				s3List(args)
+				s3List(withDefaultBucket(args, 2))
 			} else if args[0] == "create" {
-				s3Create(args)
+				s3Create(withDefaultBucket(args, 2))
 			} else if args[0] == "delete" {
 				s3Delete(args)
 			} else if args[0] == "upload" {
-				s3Upload(args)
+				s3Upload(withDefaultBucket(args, 3))
 			} else {
-				fmt.Printf("WARNING: unsupported option(s) %+v\n", args)
+				unsupportedOptions(args, s3Usage)
 			}
 		},
 	}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	authz "github.com/OreCast/common/authz"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// tokenExpirySkew defines how long before token expiration we consider it expired
+const tokenExpirySkew = 60 * time.Second
+
+// Session represents cached OreCast token obtained from Authz service
+type Session struct {
+	Context      string `json:"context,omitempty"`
+	Login        string `json:"login"`
+	AuthzURL     string `json:"authz_url"`
+	ClientId     string `json:"client_id"`
+	Scope        string `json:"scope"`
+	Grant        string `json:"grant,omitempty"` // login flow of the session: password, device, browser or api_key
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Expires      int64  `json:"expires"`
+}
+
+// Valid checks if session token exists and is not (nearly) expired
+func (s *Session) Valid() bool {
+	if s.AccessToken == "" {
+		return false
+	}
+	return time.Now().Add(tokenExpirySkew).Unix() < s.Expires
+}
+
+// Refreshable checks if session has refresh token which is not expired, opaque
+// refresh tokens without exp claim are considered refreshable
+func (s *Session) Refreshable() bool {
+	if s.RefreshToken == "" {
+		return false
+	}
+	expires, err := tokenExpires(s.RefreshToken)
+	return err != nil || time.Now().Unix() < expires
+}
+
+// ExpiresWithin checks if session token expires within given time window
+func (s *Session) ExpiresWithin(window time.Duration) bool {
+	return time.Now().Add(window).Unix() >= s.Expires
+}
+
+// helper function to return location of token cache file
+func sessionFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orecast", "tokens.json"), nil
+}
+
+// helper function to construct session key prefix from context name, Authz URL and client id
+func sessionPrefix() string {
+	return fmt.Sprintf("%s|%s|%s|", currentContext(), _oreConfig.Services.AuthzURL, _oreConfig.Authz.ClientId)
+}
+
+// helper function to return source of credentials which obtain tokens, tokens
+// obtained with API key are bound to the hash of the key such that they are
+// never mixed with user sessions and are not reused once the key is rotated
+func credentialSource() string {
+	if key := apiKey(); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "apikey:" + hex.EncodeToString(sum[:8])
+	}
+	return "user"
+}
+
+// helper function to construct session key from Authz URL, client id,
+// credential source and token scope
+func sessionKey(scope string) string {
+	return sessionPrefix() + credentialSource() + "|" + scope
+}
+
+// helper function to read all cached sessions
+func readSessions() (map[string]Session, error) {
+	sessions := make(map[string]Session)
+	fname, err := sessionFile()
+	if err != nil {
+		return sessions, err
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return sessions, nil
+		}
+		return sessions, err
+	}
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return sessions, err
+	}
+	return sessions, nil
+}
+
+// helper function to write all sessions to token cache file
+func writeSessions(sessions map[string]Session) error {
+	fname, err := sessionFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write to temporary file first and then rename it to avoid partially
+	// written cache, the file should be only readable by its owner
+	tmp := fname + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fname)
+}
+
+// scopeRanks orders token scopes, token of higher scope covers lower scopes
+var scopeRanks = map[string]int{scopeRead: 1, scopeWrite: 2, scopeAdmin: 3}
+
+// helper function to check if token of given scope covers requested scope
+func scopeCovers(have, want string) bool {
+	if have == want {
+		return true
+	}
+	h, w := scopeRanks[have], scopeRanks[want]
+	return h > 0 && w > 0 && h >= w
+}
+
+// helper function to load sessions whose scope covers given scope for current
+// Authz URL and client id, sessions are ordered from the narrowest scope
+func coveringSessions(scope string) []Session {
+	var out []Session
+	sessions, err := userSessions()
+	if err != nil {
+		return out
+	}
+	for _, s := range sessions {
+		if scopeCovers(s.Scope, scope) {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Scope == scope || out[j].Scope == scope {
+			return out[i].Scope == scope && out[j].Scope != scope
+		}
+		return scopeRanks[out[i].Scope] < scopeRanks[out[j].Scope]
+	})
+	return out
+}
+
+// helper function to load sessions of all scopes for current Authz URL, client
+// id and credential source
+func userSessions() ([]Session, error) {
+	return cachedSessions(sessionPrefix() + credentialSource() + "|")
+}
+
+// helper function to load sessions of all scopes for current Authz URL and
+// client id obtained with any credentials
+func contextSessions() ([]Session, error) {
+	return cachedSessions(sessionPrefix())
+}
+
+// errNoSession is returned when token cache has no matching session
+var errNoSession = errors.New("no cached session")
+
+// helper function to load sessions whose key starts with given prefix
+func cachedSessions(prefix string) ([]Session, error) {
+	var out []Session
+	sessions, err := readSessions()
+	if err != nil {
+		return out, err
+	}
+	for key, s := range sessions {
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Scope < out[j].Scope })
+	if len(out) == 0 {
+		return out, errNoSession
+	}
+	return out, nil
+}
+
+// helper function to store session in token cache, it fills session expiration
+// from token exp claim as well as Authz URL and client id of the session
+func saveSession(session Session) (Session, error) {
+	expires, err := tokenExpires(session.AccessToken)
+	if err != nil {
+		return session, err
+	}
+	sessions, err := readSessions()
+	if err != nil {
+		return session, err
+	}
+	session.Context = currentContext()
+	session.AuthzURL = _oreConfig.Services.AuthzURL
+	session.ClientId = _oreConfig.Authz.ClientId
+	session.Expires = expires
+	sessions[sessionKey(session.Scope)] = session
+	return session, writeSessions(sessions)
+}
+
+// helper function to remove sessions of current Authz URL and client id from token cache
+func deleteSessions() error {
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+	for key := range sessions {
+		if strings.HasPrefix(key, sessionPrefix()) {
+			delete(sessions, key)
+		}
+	}
+	return writeSessions(sessions)
+}
+
+// helper function to extract expiration time from token exp claim
+// the token signature is verified by getToken when token is obtained
+func tokenExpires(token string) (int64, error) {
+	claims := &authz.Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return 0, err
+	}
+	if claims.ExpiresAt == nil {
+		return 0, errors.New("token does not have exp claim")
+	}
+	return claims.ExpiresAt.Unix(), nil
+}
+
+// helper function to extract user login from token login or sub claims
+func tokenLogin(token string) string {
+	claims := &authz.Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	if claims.Login != "" {
+		return claims.Login
+	}
+	return claims.Subject
+}
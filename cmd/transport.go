@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	oreClient "github.com/OreCast/client/client"
+)
+
+// Used for timeout flag.
+var timeout time.Duration
+
+// interruptGrace defines how long we wait for command to clean up after
+// it was interrupted before we terminate it
+const interruptGrace = 2 * time.Second
+
+// context of running command, it is cancelled on Ctrl-C
+var _ctx = context.Background()
+
+// shared HTTP client used by all commands
+var _httpClient *http.Client
+
+// helper function to create context which is cancelled on Ctrl-C or SIGTERM,
+// the in-flight requests are cancelled and command is terminated if it does
+// not finish within grace period, e.g. when it waits for user input
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		cancel()
+		// restore default behavior such that second Ctrl-C terminates immediately
+		signal.Stop(sigs)
+		time.Sleep(interruptGrace)
+		fmt.Fprintln(os.Stderr, "interrupted")
+		os.Exit(130)
+	}()
+	return ctx
+}
+
+// helper function to return context of running command
+func cmdContext() context.Context {
+	return _ctx
+}
+
+// helper function to check if command was interrupted
+func interrupted() bool {
+	return _ctx.Err() != nil
+}
+
+// helper function to return TLS and proxy options from client configuration
+func transportOptions() oreClient.TransportOptions {
+	return oreClient.TransportOptions{
+		CABundle:           _clientConfig.CABundle,
+		ClientCert:         _clientConfig.ClientCert,
+		ClientKey:          _clientConfig.ClientKey,
+		ProxyURL:           _clientConfig.Proxy,
+		InsecureSkipVerify: _clientConfig.Insecure,
+	}
+}
+
+// helper function to create HTTP transport with given timeout which uses
+// configured TLS and proxy options
+func newTransport(timeout time.Duration) (*http.Transport, error) {
+	if timeout <= 0 {
+		timeout = oreClient.DefaultTimeout
+	}
+	t := oreClient.NewTransport(timeout)
+	err := transportOptions().Configure(t)
+	return t, err
+}
+
+// errTransportConfig is returned by requests when TLS or proxy configuration is invalid
+var errTransportConfig = errors.New("invalid TLS or proxy configuration")
+
+// errorTransport is HTTP transport which fails every request with given error
+type errorTransport struct {
+	err error
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (t errorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// helper function to create HTTP client with given timeout and number of retries
+func newHTTPClient(timeout time.Duration, retries int) (*http.Client, error) {
+	t, err := newTransport(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errTransportConfig, err)
+	}
+	return &http.Client{Transport: cassetteTransport(&oreClient.RetryTransport{
+		Base:    &oreClient.IdleTimeoutTransport{Base: t, Timeout: timeout},
+		Retries: retries,
+	})}, nil
+}
+
+// helper function to create shared HTTP client, invalid TLS or proxy
+// configuration is returned by every request of the client such that commands
+// which do not send requests, e.g. config or doctor, can still run. It warns
+// user when verification of server certificates is disabled.
+func setupHTTPClient() {
+	client, err := newHTTPClient(timeout, oreClient.DefaultRetries)
+	if err != nil {
+		client = &http.Client{Transport: errorTransport{err}}
+	}
+	_httpClient = client
+	if _clientConfig.Insecure {
+		fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is disabled by insecure_skip_verify,")
+		fmt.Fprintln(os.Stderr, "WARNING: connections to OreCast services are NOT secure, do not use it in production")
+	}
+}
+
+// helper function to return shared HTTP client
+func httpClient() *http.Client {
+	if _httpClient == nil {
+		setupHTTPClient()
+	}
+	return _httpClient
+}
+
+// helper function to send HTTP GET request with shared HTTP client
+func httpGet(rurl string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(cmdContext(), http.MethodGet, rurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpClient().Do(req)
+}
+
+// helper function to send HTTP POST request with shared HTTP client
+func httpPost(rurl, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(cmdContext(), http.MethodPost, rurl, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return httpClient().Do(req)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHTTPClientConfigError tests that invalid TLS configuration is returned
+// by requests of shared HTTP client instead of terminating the process
+func TestHTTPClientConfigError(t *testing.T) {
+	savedConfig, savedClient := _clientConfig, _httpClient
+	defer func() { _clientConfig, _httpClient = savedConfig, savedClient }()
+	_clientConfig = ClientConfig{ClientCert: "/nonexistent/cert.pem"}
+	_httpClient = nil
+
+	if _, err := newHTTPClient(doctorTimeout, 0); !errors.Is(err, errTransportConfig) {
+		t.Errorf("newHTTPClient error %v, want %v", err, errTransportConfig)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, err := httpGet(srv.URL)
+	if !errors.Is(err, errTransportConfig) {
+		t.Fatalf("request error %v, want %v", err, errTransportConfig)
+	}
+	if code := exitCode(err); code != exitFailure {
+		t.Errorf("exit code %d, want %d", code, exitFailure)
+	}
+}
@@ -1,6 +1,195 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// helper function to provide usage of user option
+func userUsage() {
+	fmt.Println("orecast user <ls|add|rm|passwd|whoami|role> [value]")
+	fmt.Println("Examples:")
+	fmt.Println("\n# list all users (requires admin rights):")
+	fmt.Println("orecast user ls")
+	fmt.Println("\n# add new user (requires admin rights):")
+	fmt.Println("orecast user add bob")
+	fmt.Println("\n# remove user (requires admin rights):")
+	fmt.Println("orecast user rm bob")
+	fmt.Println("\n# change your own password:")
+	fmt.Println("orecast user passwd")
+	fmt.Println("\n# show identity and roles of current token:")
+	fmt.Println("orecast user whoami")
+	fmt.Println("\n# list roles of the user:")
+	fmt.Println("orecast user role ls bob")
+	fmt.Println("\n# grant or revoke role of the user (requires admin rights):")
+	fmt.Println("orecast user role grant bob data-producer")
+	fmt.Println("orecast user role revoke bob data-producer")
+}
+
+// helper function to prompt for new password and its confirmation
+func newPasswordPrompt() string {
+	pass := passwordPrompt("New password:")
+	if pass == "" {
+		fmt.Println("ERROR: empty password")
+		os.Exit(1)
+	}
+	if passwordPrompt("Repeat new password:") != pass {
+		fmt.Println("ERROR: passwords do not match")
+		os.Exit(1)
+	}
+	addSecret(pass)
+	return pass
+}
+
+// helper function to list users
+func userList(args []string) {
+	users, err := orecastClient().ListUsers(cmdContext())
+	if err != nil {
+		exitError(err)
+	}
+	for _, u := range users {
+		fmt.Println("---")
+		fmt.Printf("Login      : %s\n", u.Login)
+		if len(u.Roles) > 0 {
+			fmt.Printf("Roles      : %s\n", strings.Join(u.Roles, ", "))
+		}
+		if len(u.Groups) > 0 {
+			fmt.Printf("Groups     : %s\n", strings.Join(u.Groups, ", "))
+		}
+	}
+}
+
+// helper function to add new user
+func userAdd(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(userUsage)
+	}
+	// obtain token before prompting for user password
+	if _, err := accessToken(scopeAdmin); err != nil {
+		exitError(err)
+	}
+	user := User{Login: args[1], Password: newPasswordPrompt()}
+	if err := orecastClient().AddUser(cmdContext(), user); err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: user %s was successfully added\n", user.Login)
+}
+
+// helper function to remove user
+func userDelete(args []string) {
+	if len(args) != 2 {
+		exitWithUsage(userUsage)
+	}
+	login := args[1]
+	if err := orecastClient().DeleteUser(cmdContext(), login); err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: user %s was successfully removed\n", login)
+}
+
+// helper function to change password of current user, the current
+// password is verified by Authz service before it is changed
+func userPasswd(args []string) {
+	login, pass, err := credentials()
+	if err != nil {
+		exitError(err)
+	}
+	token, err := getToken(login, pass, scopeWrite)
+	if err != nil {
+		exitError(err)
+	}
+	storeSession(Session{
+		Login:        login,
+		Scope:        scopeWrite,
+		Grant:        passwordGrant,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	})
+	if err := orecastClient().ChangePassword(cmdContext(), login, newPasswordPrompt()); err != nil {
+		exitError(err)
+	}
+	fmt.Printf("SUCCESS: password of user %s was successfully changed\n", login)
+}
+
+// helper function to show identity and roles of current token
+func userWhoami(args []string) {
+	token, err := accessToken(scopeRead)
+	if err != nil {
+		exitError(err)
+	}
+	info, err := decodeToken(token, false)
+	if err != nil {
+		exitError(err)
+	}
+	fmt.Printf("Login      : %s\n", info.Login)
+	fmt.Printf("Subject    : %s\n", info.Subject)
+	fmt.Printf("Issuer     : %s\n", claimString(info.Claims, "iss"))
+	fmt.Printf("Roles      : %s\n", claimString(info.Claims, "roles"))
+	fmt.Printf("Groups     : %s\n", claimString(info.Claims, "groups"))
+	fmt.Printf("Scope      : %s\n", info.Scope)
+	fmt.Printf("Permissions: %s\n", tokenPermissions(info))
+	fmt.Printf("Expires at : %s\n", formatTime(info.ExpiresAt))
+}
+
+// helper function to describe operations allowed by token scope
+func tokenPermissions(info TokenInfo) string {
+	var perms []string
+	for _, scope := range strings.Fields(info.Scope) {
+		switch scope {
+		case scopeRead:
+			perms = append(perms, "list records")
+		case scopeWrite:
+			perms = append(perms, "add/remove records")
+		case scopeAdmin:
+			perms = append(perms, "manage sites and users")
+		default:
+			perms = append(perms, scope)
+		}
+	}
+	if perms == nil {
+		return "none"
+	}
+	return strings.Join(perms, ", ")
+}
+
+// helper function to manage user roles
+func userRole(args []string) {
+	// args contains [role ls|grant|revoke user [role]]
+	if len(args) < 3 {
+		exitWithUsage(userUsage)
+	}
+	action, login := args[1], args[2]
+	if action == "ls" {
+		roles, err := orecastClient().UserRoles(cmdContext(), login)
+		if err != nil {
+			exitError(err)
+		}
+		for _, role := range roles {
+			fmt.Println(role)
+		}
+		return
+	}
+	if len(args) != 4 {
+		exitWithUsage(userUsage)
+	}
+	role := args[3]
+	if action == "grant" {
+		if err := orecastClient().GrantRole(cmdContext(), login, role); err != nil {
+			exitError(err)
+		}
+		fmt.Printf("SUCCESS: role %s was granted to user %s\n", role, login)
+	} else if action == "revoke" {
+		if err := orecastClient().RevokeRole(cmdContext(), login, role); err != nil {
+			exitError(err)
+		}
+		fmt.Printf("SUCCESS: role %s was revoked from user %s\n", role, login)
+	} else {
+		unsupportedOptions(args, userUsage)
+	}
+}
 
 func userCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -8,9 +197,30 @@ func userCommand() *cobra.Command {
 		Short: "OreCast user command",
 		Long: `OreCast user command
                 Complete documentation is available at https://orecast.com/documentation/`,
+		Args: cobra.MinimumNArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
-			// Do Stuff Here
+			if len(args) == 0 {
+				userUsage()
+			} else if args[0] == "ls" {
+				userList(args)
+			} else if args[0] == "add" {
+				userAdd(args)
+			} else if args[0] == "rm" {
+				userDelete(args)
+			} else if args[0] == "passwd" {
+				userPasswd(args)
+			} else if args[0] == "whoami" {
+				userWhoami(args)
+			} else if args[0] == "role" {
+				userRole(args)
+			} else {
+				unsupportedOptions(args, userUsage)
+			}
 		},
 	}
+	cmd.SetUsageFunc(func(*cobra.Command) error {
+		userUsage()
+		return nil
+	})
 	return cmd
 }
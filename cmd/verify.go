@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	authz "github.com/OreCast/common/authz"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// JWK represents single JSON web key returned by JWKS endpoint
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS represents JSON web key set returned by JWKS endpoint
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// helper function to return JWKS endpoint of Authz service
+func jwksURL() string {
+	if _clientConfig.JWKSURL != "" {
+		return _clientConfig.JWKSURL
+	}
+	return fmt.Sprintf("%s/.well-known/jwks.json", _oreConfig.Services.AuthzURL)
+}
+
+// helper function to return location of JWKS cache file
+func jwksFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "orecast", "jwks.json"), nil
+}
+
+// helper function to read locally cached JWKS keys of given JWKS URL
+func readJWKSCache(rurl string) (JWKS, error) {
+	var jwks JWKS
+	cache := make(map[string]JWKS)
+	fname, err := jwksFile()
+	if err != nil {
+		return jwks, err
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return jwks, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return jwks, err
+	}
+	if jwks, ok := cache[rurl]; ok {
+		return jwks, nil
+	}
+	return jwks, errors.New("no cached JWKS keys")
+}
+
+// helper function to store JWKS keys of given JWKS URL in local cache
+func writeJWKSCache(rurl string, jwks JWKS) error {
+	cache := make(map[string]JWKS)
+	fname, err := jwksFile()
+	if err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(fname); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	cache[rurl] = jwks
+	if err := os.MkdirAll(filepath.Dir(fname), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fname, data, 0600)
+}
+
+// helper function to fetch JWKS keys from Authz service
+func fetchJWKS(rurl string) (JWKS, error) {
+	var jwks JWKS
+	if verbose > 0 {
+		debugPrintln("HTTP GET", rurl)
+	}
+	resp, err := httpGet(rurl)
+	if err != nil {
+		return jwks, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return jwks, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return jwks, fmt.Errorf("unable to fetch JWKS keys from %s, status %s", rurl, resp.Status)
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return jwks, err
+	}
+	if err := writeJWKSCache(rurl, jwks); err != nil && verbose > 0 {
+		fmt.Println("WARNING: unable to cache JWKS keys", err)
+	}
+	return jwks, nil
+}
+
+// helper function to decode base64url encoded big integer
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// PublicKey converts JWK into RSA or ECDSA public key
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported JWK curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported JWK key type %s", k.Kty)
+}
+
+// helper function to find key in JWKS keys
+func findJWK(jwks JWKS, kid string) (JWK, bool) {
+	for _, k := range jwks.Keys {
+		if k.Kid == kid || (kid == "" && len(jwks.Keys) == 1) {
+			return k, true
+		}
+	}
+	return JWK{}, false
+}
+
+// helper function to obtain JWKS public key for given key id, it uses local
+// JWKS cache and refreshes it from Authz service on key id mismatch
+func jwksKey(kid string) (crypto.PublicKey, error) {
+	rurl := jwksURL()
+	if jwks, err := readJWKSCache(rurl); err == nil {
+		if k, ok := findJWK(jwks, kid); ok {
+			return k.PublicKey()
+		}
+	}
+	jwks, err := fetchJWKS(rurl)
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := findJWK(jwks, kid); ok {
+		return k.PublicKey()
+	}
+	return nil, fmt.Errorf("no key with kid '%s' found in JWKS %s", kid, rurl)
+}
+
+// helper function to read PEM public key from configured file
+func pemKey(alg string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(_clientConfig.TokenPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(alg, "ES") {
+		return jwt.ParseECPublicKeyFromPEM(data)
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(data)
+}
+
+// errNoTokenSecret is returned when HS256 token can't be verified since no
+// shared secret is configured
+var errNoTokenSecret = errors.New("no client.token_secret configured to verify HMAC signed token, set it or enable client.legacy_token_key for legacy Authz service")
+
+// errInvalidSignature is returned when token signature does not match verification key
+var errInvalidSignature = errors.New("invalid token signature")
+
+// helper function to check if HMAC signed token is only verified with public
+// client id of legacy Authz service
+func legacyTokenKey(token string) bool {
+	if _clientConfig.TokenSecret != "" || !_clientConfig.LegacyTokenKey {
+		return false
+	}
+	tkn, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	_, ok := tkn.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// helper function to provide key to verify token signature based on its algorithm
+func verificationKey(token *jwt.Token) (any, error) {
+	alg, _ := token.Header["alg"].(string)
+	kid, _ := token.Header["kid"].(string)
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if _clientConfig.TokenSecret != "" {
+			return []byte(_clientConfig.TokenSecret), nil
+		}
+		if !_clientConfig.LegacyTokenKey {
+			return nil, errNoTokenSecret
+		}
+		// legacy Authz service signs tokens with client id which is public,
+		// such signature does not prove that token was issued by Authz service
+		if verbose > 0 {
+			fmt.Println("WARNING: no client.token_secret configured, verify token with client id")
+		}
+		return []byte(_oreConfig.Authz.ClientId), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		if _clientConfig.TokenPublicKey != "" {
+			return pemKey(alg)
+		}
+		return jwksKey(kid)
+	}
+	return nil, fmt.Errorf("unsupported token signing algorithm '%s'", alg)
+}
+
+// helper function to verify token signature and parse its claims
+func parseToken(reqToken string, claims jwt.Claims) error {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		"HS256", "HS384", "HS512",
+		"RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512",
+	}))
+	tkn, err := parser.ParseWithClaims(reqToken, claims, verificationKey)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			return errInvalidSignature
+		}
+		if tkn != nil {
+			if alg, ok := tkn.Header["alg"].(string); ok && tkn.Method == nil {
+				return fmt.Errorf("unknown token signing algorithm '%s'", alg)
+			}
+		}
+		return err
+	}
+	if !tkn.Valid {
+		return errors.New("invalid token validity")
+	}
+	return nil
+}
+
+// helper function to validate token signature and its claims
+func validateToken(reqToken string) error {
+	return parseToken(reqToken, &authz.Claims{})
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oreConfig "github.com/OreCast/common/config"
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+// helper function to encode big integer as base64url string used by JWK
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// helper function to convert public key into JWK
+func testJWK(t *testing.T, kid string, key crypto.PublicKey) JWK {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kid: kid, Kty: "RSA", N: encodeBigInt(k.N), E: encodeBigInt(big.NewInt(int64(k.E)))}
+	case *ecdsa.PublicKey:
+		return JWK{Kid: kid, Kty: "EC", Crv: k.Curve.Params().Name, X: encodeBigInt(k.X), Y: encodeBigInt(k.Y)}
+	}
+	t.Fatalf("unsupported key %T", key)
+	return JWK{}
+}
+
+// TestJWKPublicKey tests conversion of JWK into public key
+func TestJWKPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []crypto.PublicKey
+	keys = append(keys, &rsaKey.PublicKey)
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, &ecKey.PublicKey)
+	}
+	type equaler interface {
+		Equal(crypto.PublicKey) bool
+	}
+	for _, key := range keys {
+		jwk := testJWK(t, "kid", key)
+		got, err := jwk.PublicKey()
+		if err != nil {
+			t.Errorf("%s %s: %v", jwk.Kty, jwk.Crv, err)
+			continue
+		}
+		if !key.(equaler).Equal(got) {
+			t.Errorf("%s %s: public key does not match", jwk.Kty, jwk.Crv)
+		}
+	}
+
+	invalid := []struct {
+		name string
+		jwk  JWK
+	}{
+		{"unsupported key type", JWK{Kty: "oct"}},
+		{"unsupported curve", JWK{Kty: "EC", Crv: "P-192", X: "AQ", Y: "AQ"}},
+		{"invalid modulus", JWK{Kty: "RSA", N: "!!", E: "AQAB"}},
+		{"invalid exponent", JWK{Kty: "RSA", N: "AQAB", E: "!!"}},
+		{"invalid coordinate", JWK{Kty: "EC", Crv: "P-256", X: "!!", Y: "AQ"}},
+	}
+	for _, tt := range invalid {
+		if _, err := tt.jwk.PublicKey(); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+// helper function to set token verification configuration for a test
+func setVerifyConfig(t *testing.T, config ClientConfig, clientId string) {
+	savedClient, savedOre := _clientConfig, _oreConfig
+	t.Cleanup(func() { _clientConfig, _oreConfig = savedClient, savedOre })
+	_clientConfig = config
+	_oreConfig = &oreConfig.OreCastConfig{Authz: oreConfig.Authz{ClientId: clientId}}
+	// isolate JWKS cache of the test
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+}
+
+// helper function to sign test token with given method, key and key id
+func signTestToken(t *testing.T, method jwt.SigningMethod, key any, kid string) string {
+	tkn := jwt.NewWithClaims(method, jwt.MapClaims{
+		"sub": "bob",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if kid != "" {
+		tkn.Header["kid"] = kid
+	}
+	token, err := tkn.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestVerificationKeyHMAC tests verification of HMAC signed tokens
+func TestVerificationKeyHMAC(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ClientConfig
+		key     string // key used to sign the token
+		err     error  // expected error, nil if token is valid
+		legacy  bool   // expected result of legacyTokenKey
+		wantErr bool
+	}{
+		{"token secret", ClientConfig{TokenSecret: "tsecret"}, "tsecret", nil, false, false},
+		{"wrong token secret", ClientConfig{TokenSecret: "tsecret"}, "other", errInvalidSignature, false, true},
+		{"no token secret", ClientConfig{}, "client_id", errNoTokenSecret, false, true},
+		{"legacy client id key", ClientConfig{LegacyTokenKey: true}, "client_id", nil, true, false},
+		{"legacy with wrong key", ClientConfig{LegacyTokenKey: true}, "other", errInvalidSignature, true, true},
+		{"token secret takes precedence over legacy key", ClientConfig{TokenSecret: "tsecret", LegacyTokenKey: true}, "client_id", errInvalidSignature, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVerifyConfig(t, tt.config, "client_id")
+			token := signTestToken(t, jwt.SigningMethodHS256, []byte(tt.key), "")
+			err := validateToken(token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+			if got := legacyTokenKey(token); got != tt.legacy {
+				t.Errorf("legacyTokenKey %v, want %v", got, tt.legacy)
+			}
+		})
+	}
+}
+
+// TestVerificationKeyJWKS tests verification of RSA and ECDSA signed tokens
+// with keys fetched from JWKS endpoint
+func TestVerificationKeyJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := JWKS{Keys: []JWK{
+		testJWK(t, "rsa-1", &rsaKey.PublicKey),
+		testJWK(t, "ec-1", &ecKey.PublicKey),
+	}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		key     any
+		kid     string
+		wantErr bool
+	}{
+		{"RS256", jwt.SigningMethodRS256, rsaKey, "rsa-1", false},
+		{"ES256", jwt.SigningMethodES256, ecKey, "ec-1", false},
+		{"unknown kid", jwt.SigningMethodRS256, rsaKey, "rsa-2", true},
+		{"wrong key", jwt.SigningMethodRS256, otherKey, "rsa-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVerifyConfig(t, ClientConfig{JWKSURL: srv.URL}, "client_id")
+			token := signTestToken(t, tt.method, tt.key, tt.kid)
+			if err := validateToken(token); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+// TestVerificationKeyPEM tests verification of RSA signed token with public
+// key read from PEM file
+func TestVerificationKeyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fname := filepath.Join(t.TempDir(), "public.pem")
+	if err := os.WriteFile(fname, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setVerifyConfig(t, ClientConfig{TokenPublicKey: fname}, "client_id")
+	if err := validateToken(signTestToken(t, jwt.SigningMethodRS256, rsaKey, "")); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateToken(signTestToken(t, jwt.SigningMethodRS256, otherKey, "")); !errors.Is(err, errInvalidSignature) {
+		t.Errorf("error %v, want %v", err, errInvalidSignature)
+	}
+}
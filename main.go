@@ -1,9 +1,14 @@
 package main
 
 import (
+	"os"
+
 	cmd "github.com/OreCast/client/cmd"
 )
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		// cobra already printed the error and usage of the command
+		os.Exit(2)
+	}
 }